@@ -0,0 +1,398 @@
+// Package apierror defines the errors returned by the payOS client.
+//
+// Typed errors can be matched with errors.As, and every typed API error also
+// matches *APIError:
+//
+//	var apiErr *apierror.APIError
+//	if errors.As(err, &apiErr) {
+//	    fmt.Println(apiErr.StatusCode, apiErr.Code)
+//	}
+//
+// Sentinel values allow branching on the error category with errors.Is:
+//
+//	if errors.Is(err, apierror.ErrTooManyRequests) {
+//	    // back off
+//	}
+//
+// Known payOS business codes are listed in a catalog that maps each code to a
+// sentinel, a retryability class and Vietnamese and English messages:
+//
+//	if errors.Is(err, apierror.ErrOrderCodeExists) {
+//	    // reuse the existing payment link
+//	}
+package apierror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Sentinel errors usable with errors.Is to match an error category
+var (
+	ErrBadRequest        = errors.New("payos: bad request")
+	ErrUnauthorized      = errors.New("payos: unauthorized")
+	ErrForbidden         = errors.New("payos: forbidden")
+	ErrNotFound          = errors.New("payos: not found")
+	ErrTooManyRequests   = errors.New("payos: too many requests")
+	ErrInternalServer    = errors.New("payos: internal server error")
+	ErrConnection        = errors.New("payos: connection error")
+	ErrConnectionTimeout = errors.New("payos: connection timeout")
+	ErrInvalidSignature  = errors.New("payos: invalid signature")
+	ErrWebhook           = errors.New("payos: webhook error")
+	ErrDuplicateWebhook  = errors.New("payos: duplicate webhook")
+	ErrCircuitOpen       = errors.New("payos: circuit breaker open")
+	ErrCredentials       = errors.New("payos: credentials error")
+)
+
+// PayOSError is the base error type for all PayOS errors
+type PayOSError struct {
+	message string
+}
+
+func NewPayOSError(message string) *PayOSError {
+	return &PayOSError{
+		message: message,
+	}
+}
+
+func (e *PayOSError) Error() string {
+	return e.message
+}
+
+// APIError represents an error returned by the PayOS API
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+	Headers    http.Header
+}
+
+func NewAPIError(statusCode int, code, message string, headers http.Header) *APIError {
+	return &APIError{
+		StatusCode: statusCode,
+		Code:       code,
+		Message:    message,
+		Headers:    headers,
+	}
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error (status %d, code %s): %s", e.StatusCode, e.Code, e.Message)
+}
+
+// Is reports whether the status code or business code of the error matches the target sentinel
+func (e *APIError) Is(target error) bool {
+	if e.isCode(target) {
+		return true
+	}
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrTooManyRequests:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrInternalServer:
+		return e.StatusCode >= http.StatusInternalServerError
+	}
+	return false
+}
+
+// BadRequestError represents a 400 error
+type BadRequestError struct {
+	*APIError
+}
+
+func NewBadRequestError(code, message string, headers http.Header) *BadRequestError {
+	return &BadRequestError{
+		APIError: NewAPIError(400, code, message, headers),
+	}
+}
+
+// Unwrap returns the underlying *APIError
+func (e *BadRequestError) Unwrap() error {
+	return e.APIError
+}
+
+// UnauthorizedError represents a 401 error
+type UnauthorizedError struct {
+	*APIError
+}
+
+func NewUnauthorizedError(code, message string, headers http.Header) *UnauthorizedError {
+	return &UnauthorizedError{
+		APIError: NewAPIError(401, code, message, headers),
+	}
+}
+
+// Unwrap returns the underlying *APIError
+func (e *UnauthorizedError) Unwrap() error {
+	return e.APIError
+}
+
+// ForbiddenError represents a 403 error
+type ForbiddenError struct {
+	*APIError
+}
+
+func NewForbiddenError(code, message string, headers http.Header) *ForbiddenError {
+	return &ForbiddenError{
+		APIError: NewAPIError(403, code, message, headers),
+	}
+}
+
+// Unwrap returns the underlying *APIError
+func (e *ForbiddenError) Unwrap() error {
+	return e.APIError
+}
+
+// NotFoundError represents a 404 error
+type NotFoundError struct {
+	*APIError
+}
+
+func NewNotFoundError(code, message string, headers http.Header) *NotFoundError {
+	return &NotFoundError{
+		APIError: NewAPIError(404, code, message, headers),
+	}
+}
+
+// Unwrap returns the underlying *APIError
+func (e *NotFoundError) Unwrap() error {
+	return e.APIError
+}
+
+// TooManyRequestError represents a 429 error
+type TooManyRequestError struct {
+	*APIError
+}
+
+func NewTooManyRequestError(code, message string, headers http.Header) *TooManyRequestError {
+	return &TooManyRequestError{
+		APIError: NewAPIError(429, code, message, headers),
+	}
+}
+
+// Unwrap returns the underlying *APIError
+func (e *TooManyRequestError) Unwrap() error {
+	return e.APIError
+}
+
+// InternalServerError represents a 500+ error
+type InternalServerError struct {
+	*APIError
+}
+
+func NewInternalServerError(statusCode int, code, message string, headers http.Header) *InternalServerError {
+	return &InternalServerError{
+		APIError: NewAPIError(statusCode, code, message, headers),
+	}
+}
+
+// Unwrap returns the underlying *APIError
+func (e *InternalServerError) Unwrap() error {
+	return e.APIError
+}
+
+// ConnectionError represents a network connection error
+type ConnectionError struct {
+	Message string
+	Err     error
+}
+
+func NewConnectionError(message string, err error) *ConnectionError {
+	return &ConnectionError{
+		Message: message,
+		Err:     err,
+	}
+}
+
+func (e *ConnectionError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("connection error: %s - %v", e.Message, e.Err)
+	}
+	return fmt.Sprintf("connection error: %s", e.Message)
+}
+
+func (e *ConnectionError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the target is ErrConnection
+func (e *ConnectionError) Is(target error) bool {
+	return target == ErrConnection
+}
+
+// ConnectionTimeoutError represents a request timeout error
+type ConnectionTimeoutError struct {
+	Message string
+	Err     error
+}
+
+func NewConnectionTimeoutError(message string) *ConnectionTimeoutError {
+	return &ConnectionTimeoutError{
+		Message: message,
+	}
+}
+
+func (e *ConnectionTimeoutError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("connection timeout: %s", e.Message)
+	}
+	return "connection timeout"
+}
+
+func (e *ConnectionTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the target is ErrConnectionTimeout
+func (e *ConnectionTimeoutError) Is(target error) bool {
+	return target == ErrConnectionTimeout
+}
+
+// InvalidSignatureError represents a signature verification error
+type InvalidSignatureError struct {
+	Message string
+	Err     error
+}
+
+func NewInvalidSignatureError(message string) *InvalidSignatureError {
+	return &InvalidSignatureError{
+		Message: message,
+	}
+}
+
+func (e *InvalidSignatureError) Error() string {
+	return fmt.Sprintf("invalid signature: %s", e.Message)
+}
+
+func (e *InvalidSignatureError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the target is ErrInvalidSignature
+func (e *InvalidSignatureError) Is(target error) bool {
+	return target == ErrInvalidSignature
+}
+
+// WebhookError represents an error processing webhook data
+type WebhookError struct {
+	Message string
+}
+
+func NewWebhookError(message string) *WebhookError {
+	return &WebhookError{
+		Message: message,
+	}
+}
+
+func (e *WebhookError) Error() string {
+	return fmt.Sprintf("webhook error: %s", e.Message)
+}
+
+// Is reports whether the target is ErrWebhook
+func (e *WebhookError) Is(target error) bool {
+	return target == ErrWebhook
+}
+
+// DuplicateWebhookError is returned for a webhook that has already been processed
+type DuplicateWebhookError struct {
+	Key string
+}
+
+func NewDuplicateWebhookError(key string) *DuplicateWebhookError {
+	return &DuplicateWebhookError{
+		Key: key,
+	}
+}
+
+func (e *DuplicateWebhookError) Error() string {
+	return fmt.Sprintf("duplicate webhook: %s already processed", e.Key)
+}
+
+// Is reports whether the target is ErrDuplicateWebhook or ErrWebhook
+func (e *DuplicateWebhookError) Is(target error) bool {
+	return target == ErrDuplicateWebhook || target == ErrWebhook
+}
+
+// CircuitOpenError is returned without sending the request while the circuit breaker of its endpoint group is open
+type CircuitOpenError struct {
+	Group string
+
+	// RetryAt is when the circuit lets a trial request through
+	// While trial requests are in flight it assumes they fail and the circuit opens again
+	RetryAt time.Time
+}
+
+func NewCircuitOpenError(group string, retryAt time.Time) *CircuitOpenError {
+	return &CircuitOpenError{
+		Group:   group,
+		RetryAt: retryAt,
+	}
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker open for %s until %s", e.Group, e.RetryAt.Format(time.RFC3339))
+}
+
+// Is reports whether the target is ErrCircuitOpen
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// CredentialsError is returned when the credentials of a request cannot be loaded
+type CredentialsError struct {
+	Message string
+	Err     error
+}
+
+func NewCredentialsError(message string, err error) *CredentialsError {
+	return &CredentialsError{
+		Message: message,
+		Err:     err,
+	}
+}
+
+func (e *CredentialsError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("credentials error: %s - %v", e.Message, e.Err)
+	}
+	return fmt.Sprintf("credentials error: %s", e.Message)
+}
+
+func (e *CredentialsError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the target is ErrCredentials
+func (e *CredentialsError) Is(target error) bool {
+	return target == ErrCredentials
+}
+
+// GenerateError creates the appropriate error type based on status code
+func GenerateError(statusCode int, code, message string, headers http.Header) error {
+	switch statusCode {
+	case 400:
+		return NewBadRequestError(code, message, headers)
+	case 401:
+		return NewUnauthorizedError(code, message, headers)
+	case 403:
+		return NewForbiddenError(code, message, headers)
+	case 404:
+		return NewNotFoundError(code, message, headers)
+	case 429:
+		return NewTooManyRequestError(code, message, headers)
+	default:
+		if statusCode >= 500 {
+			return NewInternalServerError(statusCode, code, message, headers)
+		}
+		return NewAPIError(statusCode, code, message, headers)
+	}
+}
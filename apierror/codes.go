@@ -0,0 +1,163 @@
+package apierror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Business codes returned in the code field of payOS responses
+// Only codes published by payOS are listed; payout failures such as an insufficient balance
+// or an invalid receiving account have no published code of their own, so match them with the
+// HTTP status sentinels such as ErrBadRequest and read the reason from the message
+const (
+	CodeSuccess                  = "00"
+	CodeInvalidParams            = "01"
+	CodeInternalError            = "20"
+	CodePaymentLinkNotFound      = "101"
+	CodeInvalidSignature         = "201"
+	CodeOrderCodeExists          = "231"
+	CodePaymentLinkNotCancelable = "232"
+	CodeUnauthorized             = "401"
+	CodeTooManyRequests          = "429"
+)
+
+// Sentinel errors for payOS business codes, usable with errors.Is
+var (
+	ErrInvalidParams            = errors.New("payos: invalid params")
+	ErrPaymentLinkNotFound      = errors.New("payos: payment link not found")
+	ErrOrderCodeExists          = errors.New("payos: order code already exists")
+	ErrPaymentLinkNotCancelable = errors.New("payos: payment link cannot be cancelled")
+)
+
+// Language selects the language of catalog messages
+type Language string
+
+const (
+	English    Language = "en"
+	Vietnamese Language = "vi"
+)
+
+// CodeInfo describes a known payOS business code
+type CodeInfo struct {
+	Code string
+
+	// Err is the sentinel matched by errors.Is for API errors with this code
+	Err error
+
+	// Retryable reports whether the same request may succeed when sent again
+	Retryable bool
+
+	// Messages holds the human readable description by language
+	Messages map[Language]string
+}
+
+// catalog lists the known payOS business codes
+var catalog = map[string]CodeInfo{
+	CodeInvalidParams: {
+		Err: ErrInvalidParams,
+		Messages: map[Language]string{
+			English:    "The request parameters are invalid",
+			Vietnamese: "Tham số không hợp lệ",
+		},
+	},
+	CodeInternalError: {
+		Err:       ErrInternalServer,
+		Retryable: true,
+		Messages: map[Language]string{
+			English:    "payOS encountered an internal error",
+			Vietnamese: "Hệ thống payOS gặp lỗi",
+		},
+	},
+	CodePaymentLinkNotFound: {
+		Err: ErrPaymentLinkNotFound,
+		Messages: map[Language]string{
+			English:    "The payment link does not exist",
+			Vietnamese: "Link thanh toán không tồn tại",
+		},
+	},
+	CodeInvalidSignature: {
+		Err: ErrInvalidSignature,
+		Messages: map[Language]string{
+			English:    "The request signature is invalid, check the checksum key",
+			Vietnamese: "Chữ ký không hợp lệ, vui lòng kiểm tra checksum key",
+		},
+	},
+	CodeOrderCodeExists: {
+		Err: ErrOrderCodeExists,
+		Messages: map[Language]string{
+			English:    "A payment link already exists for this order code",
+			Vietnamese: "Đơn thanh toán đã tồn tại",
+		},
+	},
+	CodePaymentLinkNotCancelable: {
+		Err: ErrPaymentLinkNotCancelable,
+		Messages: map[Language]string{
+			English:    "The payment link is already paid, cancelled or expired",
+			Vietnamese: "Link thanh toán đã được thanh toán, đã hủy hoặc đã hết hạn",
+		},
+	},
+	CodeUnauthorized: {
+		Err: ErrUnauthorized,
+		Messages: map[Language]string{
+			English:    "The client ID or API key is invalid",
+			Vietnamese: "Client ID hoặc API key không hợp lệ",
+		},
+	},
+	CodeTooManyRequests: {
+		Err:       ErrTooManyRequests,
+		Retryable: true,
+		Messages: map[Language]string{
+			English:    "Too many requests, retry later",
+			Vietnamese: "Quá nhiều yêu cầu, vui lòng thử lại sau",
+		},
+	},
+}
+
+// LookupCode returns the catalog entry of a payOS business code
+func LookupCode(code string) (CodeInfo, bool) {
+	info, ok := catalog[code]
+	if ok {
+		info.Code = code
+	}
+	return info, ok
+}
+
+// CodeMessage returns the human readable message of a payOS business code in lang
+// Unknown codes and languages fall back to a generic English message
+func CodeMessage(code string, lang Language) string {
+	if info, ok := LookupCode(code); ok {
+		if message, ok := info.Messages[lang]; ok {
+			return message
+		}
+		return info.Messages[English]
+	}
+	return fmt.Sprintf("payOS returned error code %s", code)
+}
+
+// Retryable reports whether the request may succeed when sent again
+// 408, 429 and 5xx responses are always retryable, and known business codes can only add retryability
+func (e *APIError) Retryable() bool {
+	if e.StatusCode == http.StatusRequestTimeout ||
+		e.StatusCode == http.StatusTooManyRequests ||
+		e.StatusCode >= http.StatusInternalServerError {
+		return true
+	}
+	info, ok := LookupCode(e.Code)
+	return ok && info.Retryable
+}
+
+// LocalizedMessage returns the catalog message of the error code in lang
+// Unknown codes fall back to the message returned by payOS
+func (e *APIError) LocalizedMessage(lang Language) string {
+	if _, ok := LookupCode(e.Code); ok || e.Message == "" {
+		return CodeMessage(e.Code, lang)
+	}
+	return e.Message
+}
+
+// isCode reports whether the business code of the error maps to the target sentinel
+func (e *APIError) isCode(target error) bool {
+	info, ok := LookupCode(e.Code)
+	return ok && info.Err == target
+}
@@ -0,0 +1,72 @@
+package apierror
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLookupCode(t *testing.T) {
+	info, ok := LookupCode(CodeOrderCodeExists)
+	if !ok || info.Code != CodeOrderCodeExists || info.Err != ErrOrderCodeExists || info.Retryable {
+		t.Errorf("LookupCode(%s) = %+v, %v", CodeOrderCodeExists, info, ok)
+	}
+	if _, ok := LookupCode("999"); ok {
+		t.Error("LookupCode(999) found an unknown code")
+	}
+}
+
+func TestCodeMessage(t *testing.T) {
+	tests := []struct {
+		code string
+		lang Language
+		want string
+	}{
+		{CodeOrderCodeExists, English, "A payment link already exists for this order code"},
+		{CodeOrderCodeExists, Vietnamese, "Đơn thanh toán đã tồn tại"},
+		{CodeOrderCodeExists, Language("fr"), "A payment link already exists for this order code"},
+		{"999", Vietnamese, "payOS returned error code 999"},
+	}
+	for _, tt := range tests {
+		if got := CodeMessage(tt.code, tt.lang); got != tt.want {
+			t.Errorf("CodeMessage(%s, %s) = %q, want %q", tt.code, tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestCodeErrors(t *testing.T) {
+	tests := []struct {
+		err       *APIError
+		target    error
+		retryable bool
+	}{
+		{NewAPIError(200, CodeOrderCodeExists, "exists", nil), ErrOrderCodeExists, false},
+		{GenerateError(400, CodePaymentLinkNotCancelable, "", nil).(*BadRequestError).APIError, ErrPaymentLinkNotCancelable, false},
+		{NewAPIError(200, CodeInternalError, "", nil), ErrInternalServer, true},
+		{NewAPIError(500, CodeInvalidParams, "", nil), ErrInvalidParams, true},
+		{NewAPIError(429, CodeInvalidParams, "", nil), ErrInvalidParams, true},
+		{NewAPIError(400, CodeInvalidParams, "", nil), ErrInvalidParams, false},
+		{NewAPIError(503, "999", "", nil), ErrInternalServer, true},
+	}
+	for _, tt := range tests {
+		if !errors.Is(tt.err, tt.target) {
+			t.Errorf("errors.Is(%v, %v) = false", tt.err, tt.target)
+		}
+		if got := tt.err.Retryable(); got != tt.retryable {
+			t.Errorf("Retryable(%v) = %v, want %v", tt.err, got, tt.retryable)
+		}
+	}
+
+	// Typed errors match the code sentinels through the embedded *APIError
+	if err := GenerateError(400, CodePaymentLinkNotCancelable, "", nil); !errors.Is(err, ErrPaymentLinkNotCancelable) || !errors.Is(err, ErrBadRequest) {
+		t.Errorf("errors.Is(%v) does not match both sentinels", err)
+	}
+}
+
+func TestLocalizedMessage(t *testing.T) {
+	if got := NewAPIError(400, "999", "server message", nil).LocalizedMessage(Vietnamese); got != "server message" {
+		t.Errorf("LocalizedMessage() of unknown code = %q", got)
+	}
+	if got := NewAPIError(400, CodePaymentLinkNotFound, "not found", nil).LocalizedMessage(Vietnamese); got != "Link thanh toán không tồn tại" {
+		t.Errorf("LocalizedMessage() of known code = %q", got)
+	}
+}
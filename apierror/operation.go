@@ -0,0 +1,118 @@
+package apierror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// RequestIDHeaders are the response headers read, in order, for the request ID of an OperationError
+var RequestIDHeaders = []string{"X-Request-Id", "X-Trace-Id", "X-Amzn-Trace-Id", "Cf-Ray"}
+
+// Attempt describes a failed attempt of an API call
+type Attempt struct {
+	// Err is the error of the attempt
+	Err error
+
+	// StatusCode is the HTTP status of the attempt, or zero if no response was received
+	StatusCode int
+
+	// Backoff is the wait before the next attempt, or zero for the last attempt
+	Backoff time.Duration
+}
+
+// OperationError is returned by API calls that failed after every attempt
+// It wraps the error of the last attempt
+type OperationError struct {
+	// Op names the SDK method, such as Payouts.Create
+	Op string
+
+	Method string
+	Path   string
+
+	// IdempotencyKey is the x-idempotency-key sent with the request, if any
+	IdempotencyKey string
+
+	// RequestID is the request or trace ID returned by the server, if any
+	RequestID string
+
+	// Attempts lists every attempt in order
+	Attempts []Attempt
+
+	// Err is the final error
+	Err error
+}
+
+// NewOperationError builds an OperationError from the attempts of a call
+// The request ID is read from the headers of the latest API error
+func NewOperationError(op, method, path, idempotencyKey string, attempts []Attempt, err error) *OperationError {
+	opErr := &OperationError{
+		Op:             op,
+		Method:         method,
+		Path:           path,
+		IdempotencyKey: idempotencyKey,
+		Attempts:       attempts,
+		Err:            err,
+	}
+	for i := len(attempts) - 1; i >= 0 && opErr.RequestID == ""; i-- {
+		var apiErr *APIError
+		if errors.As(attempts[i].Err, &apiErr) {
+			opErr.RequestID = requestID(apiErr.Headers)
+		}
+	}
+	return opErr
+}
+
+func (e *OperationError) Error() string {
+	var b strings.Builder
+	b.WriteString("payos: ")
+	if e.Op != "" {
+		b.WriteString(e.Op)
+		b.WriteString(" ")
+	}
+	fmt.Fprintf(&b, "%s %s", e.Method, e.Path)
+
+	var details []string
+	if e.IdempotencyKey != "" {
+		details = append(details, "idempotency_key="+e.IdempotencyKey)
+	}
+	if e.RequestID != "" {
+		details = append(details, "request_id="+e.RequestID)
+	}
+	if len(details) > 0 {
+		fmt.Fprintf(&b, " (%s)", strings.Join(details, ", "))
+	}
+	fmt.Fprintf(&b, ": %v", e.Err)
+
+	// Earlier attempts are only listed when the call was retried
+	if len(e.Attempts) > 1 {
+		b.WriteString("; attempts:")
+		for i, attempt := range e.Attempts {
+			fmt.Fprintf(&b, " #%d %v", i+1, attempt.Err)
+			if attempt.Backoff > 0 {
+				fmt.Fprintf(&b, " +%v", attempt.Backoff)
+			}
+			if i < len(e.Attempts)-1 {
+				b.WriteString(",")
+			}
+		}
+	}
+	return b.String()
+}
+
+// Unwrap returns the final error
+func (e *OperationError) Unwrap() error {
+	return e.Err
+}
+
+// requestID returns the first request ID header set in headers
+func requestID(headers http.Header) string {
+	for _, name := range RequestIDHeaders {
+		if value := headers.Get(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
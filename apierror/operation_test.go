@@ -0,0 +1,38 @@
+package apierror
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestOperationError(t *testing.T) {
+	headers := http.Header{}
+	headers.Set("Cf-Ray", "ray-1")
+	first := GenerateError(http.StatusServiceUnavailable, CodeInternalError, "unavailable", nil)
+	last := GenerateError(http.StatusTooManyRequests, CodeTooManyRequests, "slow down", headers)
+
+	err := NewOperationError("Payouts.Get", "GET", "/v1/payouts/p1", "", []Attempt{
+		{Err: first, StatusCode: http.StatusServiceUnavailable, Backoff: 500 * time.Millisecond},
+		{Err: last, StatusCode: http.StatusTooManyRequests},
+	}, last)
+
+	if err.RequestID != "ray-1" {
+		t.Errorf("RequestID = %q, want ray-1", err.RequestID)
+	}
+	want := "payos: Payouts.Get GET /v1/payouts/p1 (request_id=ray-1): " + last.Error() +
+		"; attempts: #1 " + first.Error() + " +500ms, #2 " + last.Error()
+	if err.Error() != want {
+		t.Errorf("Error() = %q\nwant %q", err.Error(), want)
+	}
+	if !errors.Is(err, ErrTooManyRequests) || errors.Is(err, ErrInternalServer) {
+		t.Error("OperationError should only match the final error")
+	}
+
+	single := NewOperationError("", "POST", "/v2/payment-requests", "key-1", []Attempt{{Err: first}}, first)
+	want = "payos: POST /v2/payment-requests (idempotency_key=key-1): " + first.Error()
+	if single.Error() != want {
+		t.Errorf("Error() = %q\nwant %q", single.Error(), want)
+	}
+}
@@ -1,12 +1,6 @@
 package payos
 
-import (
-	"context"
-
-	"github.com/payOSHQ/payos-lib-golang/v2/internal/apierror"
-	"github.com/payOSHQ/payos-lib-golang/v2/internal/apijson"
-	"github.com/payOSHQ/payos-lib-golang/v2/internal/crypto"
-)
+import "context"
 
 // Batch handles batch payout operations
 type Batch struct {
@@ -21,27 +15,22 @@ func newBatch(client *Client) *Batch {
 }
 
 // Create creates a batch payout
-func (b *Batch) Create(ctx context.Context, payoutData PayoutBatchRequest, idempotencyKey *string) (*Payout, error) {
+func (b *Batch) Create(ctx context.Context, payoutData PayoutBatchRequest, idempotencyKey *string, opts ...RequestOption) (*Payout, error) {
+	opts = withOperation("Payouts.Batch.Create", opts)
 	// Generate idempotency key if not provided
-	key := ""
-	if idempotencyKey != nil {
-		key = *idempotencyKey
-	} else {
-		key = crypto.GenerateUUID()
-	}
-
-	result, err := b.client.Post(ctx, "/v1/payouts/batch", payoutData, &SignatureOpts{
-		Request:  "header",
-		Response: "header",
-	}, map[string]string{"x-idempotency-key": key})
+	key, err := b.client.resolveIdempotencyKey(ctx, idempotencyKey, payoutData, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	var response Payout
-	if err := apijson.ConvertInterface(result, &response); err != nil {
-		return nil, apierror.NewPayOSError("failed to parse response")
-	}
-
-	return &response, nil
+	return Do[Payout](ctx, b.client, &RequestOptions{
+		Method: "POST",
+		Path:   "/v1/payouts/batch",
+		Body:   payoutData,
+		SignatureOpts: &SignatureOpts{
+			Request:  "header",
+			Response: "header",
+		},
+		Headers: map[string]string{"x-idempotency-key": key},
+	}, opts...)
 }
@@ -0,0 +1,220 @@
+package payos
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/payOSHQ/payos-lib-golang/v2/apierror"
+)
+
+// Defaults of CircuitBreakerOptions
+const (
+	DefaultCircuitFailureThreshold = 5
+	DefaultCircuitOpenTimeout      = 30 * time.Second
+)
+
+// CircuitState is the state of the circuit of an endpoint group
+type CircuitState int
+
+const (
+	// CircuitClosed lets every request through
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails every request with apierror.ErrCircuitOpen
+	CircuitOpen
+	// CircuitHalfOpen lets trial requests through to decide whether payOS recovered
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreakerOptions configures a CircuitBreaker
+type CircuitBreakerOptions struct {
+	// FailureThreshold is the number of consecutive failures that opens the circuit
+	// Defaults to DefaultCircuitFailureThreshold
+	FailureThreshold int
+
+	// OpenTimeout is how long the circuit stays open before trial requests are let through
+	// Defaults to DefaultCircuitOpenTimeout
+	OpenTimeout time.Duration
+
+	// HalfOpenRequests is the number of trial requests in flight while half-open
+	// The circuit closes when one of them succeeds
+	// Defaults to 1
+	HalfOpenRequests int
+
+	// IsFailure reports whether an attempt counts as a failure
+	// Defaults to transport errors and 5xx responses
+	IsFailure func(resp *http.Response, err error) bool
+
+	// OnStateChange is called when the circuit of an endpoint group changes state
+	OnStateChange func(group string, from, to CircuitState)
+
+	// Clock times the open state
+	// Defaults to SystemClock
+	Clock Clock
+}
+
+// CircuitBreaker fails requests fast while payOS is failing, per endpoint group
+// After FailureThreshold consecutive failures the circuit opens and requests fail with
+// apierror.ErrCircuitOpen without being sent or retried; after OpenTimeout trial requests
+// decide whether the circuit closes again
+type CircuitBreaker struct {
+	opts CircuitBreakerOptions
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+type circuit struct {
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	trials   int
+}
+
+// NewCircuitBreaker returns a CircuitBreaker to add to PayOSOptions.Middlewares
+func NewCircuitBreaker(opts CircuitBreakerOptions) *CircuitBreaker {
+	if opts.FailureThreshold <= 0 {
+		opts.FailureThreshold = DefaultCircuitFailureThreshold
+	}
+	opts.OpenTimeout = getDurationValue(opts.OpenTimeout, DefaultCircuitOpenTimeout)
+	if opts.HalfOpenRequests <= 0 {
+		opts.HalfOpenRequests = 1
+	}
+	if opts.IsFailure == nil {
+		opts.IsFailure = isCircuitFailure
+	}
+	opts.Clock = orSystemClock(opts.Clock)
+	return &CircuitBreaker{opts: opts, circuits: make(map[string]*circuit)}
+}
+
+// Middleware returns the circuit breaker as a Middleware
+func (b *CircuitBreaker) Middleware() Middleware {
+	return func(next RequestHandler) RequestHandler {
+		return func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			group := EndpointGroup(req.URL.Path)
+			if err := b.allow(group); err != nil {
+				return nil, err
+			}
+			resp, err := next(ctx, req)
+			b.record(ctx, group, resp, err)
+			return resp, err
+		}
+	}
+}
+
+// State returns the state of the circuit of an endpoint group
+func (b *CircuitBreaker) State(group string) CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c, ok := b.circuits[group]
+	if !ok {
+		return CircuitClosed
+	}
+	if c.state == CircuitOpen && b.opts.Clock.Now().Sub(c.openedAt) >= b.opts.OpenTimeout {
+		return CircuitHalfOpen
+	}
+	return c.state
+}
+
+// allow checks whether a request of the group may be sent
+func (b *CircuitBreaker) allow(group string) error {
+	b.mu.Lock()
+	c := b.circuit(group)
+	from := c.state
+
+	if c.state == CircuitOpen {
+		retryAt := c.openedAt.Add(b.opts.OpenTimeout)
+		now := b.opts.Clock.Now()
+		if now.Before(retryAt) {
+			b.mu.Unlock()
+			return apierror.NewCircuitOpenError(group, retryAt)
+		}
+		c.state = CircuitHalfOpen
+		c.trials = 0
+	}
+	if c.state == CircuitHalfOpen {
+		if c.trials >= b.opts.HalfOpenRequests {
+			// The trials in flight decide the state, and a failed trial opens the circuit for OpenTimeout
+			retryAt := b.opts.Clock.Now().Add(b.opts.OpenTimeout)
+			b.mu.Unlock()
+			return apierror.NewCircuitOpenError(group, retryAt)
+		}
+		c.trials++
+	}
+	to := c.state
+	b.mu.Unlock()
+
+	b.notify(group, from, to)
+	return nil
+}
+
+// record updates the circuit of the group with the outcome of a request
+func (b *CircuitBreaker) record(ctx context.Context, group string, resp *http.Response, err error) {
+	// Requests cancelled by the caller say nothing about payOS
+	canceled := err != nil && ctx.Err() != nil
+	failed := !canceled && b.opts.IsFailure(resp, err)
+
+	b.mu.Lock()
+	c := b.circuit(group)
+	from := c.state
+
+	switch {
+	case c.state == CircuitHalfOpen && canceled:
+		c.trials--
+	case c.state == CircuitHalfOpen && failed:
+		c.state = CircuitOpen
+		c.openedAt = b.opts.Clock.Now()
+	case c.state == CircuitHalfOpen:
+		c.state = CircuitClosed
+		c.failures = 0
+	case canceled:
+	case failed:
+		c.failures++
+		if c.state == CircuitClosed && c.failures >= b.opts.FailureThreshold {
+			c.state = CircuitOpen
+			c.openedAt = b.opts.Clock.Now()
+		}
+	default:
+		c.failures = 0
+	}
+	to := c.state
+	b.mu.Unlock()
+
+	b.notify(group, from, to)
+}
+
+// circuit returns the circuit of a group, creating it on first use
+func (b *CircuitBreaker) circuit(group string) *circuit {
+	c, ok := b.circuits[group]
+	if !ok {
+		c = &circuit{}
+		b.circuits[group] = c
+	}
+	return c
+}
+
+// notify calls OnStateChange when the state changed
+func (b *CircuitBreaker) notify(group string, from, to CircuitState) {
+	if from != to && b.opts.OnStateChange != nil {
+		b.opts.OnStateChange(group, from, to)
+	}
+}
+
+// isCircuitFailure reports whether an attempt failed because of payOS or the network
+func isCircuitFailure(resp *http.Response, err error) bool {
+	return err != nil || resp.StatusCode >= http.StatusInternalServerError
+}
@@ -0,0 +1,158 @@
+package payos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/payOSHQ/payos-lib-golang/v2/apierror"
+	"github.com/payOSHQ/payos-lib-golang/v2/payostest"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var failing atomic.Bool
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"code":"00","desc":"success","data":null}`))
+	}))
+	defer srv.Close()
+
+	var mu sync.Mutex
+	var changes []string
+	breaker := NewCircuitBreaker(CircuitBreakerOptions{
+		FailureThreshold: 2,
+		OpenTimeout:      50 * time.Millisecond,
+		OnStateChange: func(group string, from, to CircuitState) {
+			mu.Lock()
+			changes = append(changes, fmt.Sprintf("%s:%s->%s", group, from, to))
+			mu.Unlock()
+		},
+	})
+	client, err := NewPayOS(&PayOSOptions{
+		ClientId:    "client-id",
+		ApiKey:      "api-key",
+		ChecksumKey: "checksum-key",
+		BaseURL:     srv.URL,
+		MaxRetries:  3,
+		Middlewares: []Middleware{breaker.Middleware()},
+		RetryPolicy: RetryPolicyFunc(func(ctx context.Context, attempt *RetryAttempt) (time.Duration, bool) {
+			return 0, isRetryableError(attempt.Err)
+		}),
+	})
+	if err != nil {
+		t.Fatalf("NewPayOS() error = %v", err)
+	}
+	ctx := context.Background()
+
+	// Two failed attempts open the circuit, the remaining retries fail fast
+	failing.Store(true)
+	_, err = client.PayoutsAccount.Balance(ctx)
+	var circuitErr *apierror.CircuitOpenError
+	if !errors.Is(err, apierror.ErrCircuitOpen) || !errors.As(err, &circuitErr) || circuitErr.Group != EndpointGroupPayouts {
+		t.Fatalf("Balance() error = %v, want circuit open", err)
+	}
+	var opErr *apierror.OperationError
+	if !errors.As(err, &opErr) || len(opErr.Attempts) != 3 {
+		t.Fatalf("Balance() attempts = %v", err)
+	}
+	if got := atomic.LoadInt32(&requests); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+	if state := breaker.State(EndpointGroupPayouts); state != CircuitOpen {
+		t.Errorf("State() = %v, want open", state)
+	}
+
+	// Other endpoint groups are not affected
+	if _, err := client.PaymentRequests.Get(ctx, 1, WithMaxRetries(0)); !errors.Is(err, apierror.ErrInternalServer) {
+		t.Errorf("Get() error = %v, want request sent", err)
+	}
+
+	// A successful trial after the open timeout closes the circuit
+	failing.Store(false)
+	time.Sleep(60 * time.Millisecond)
+	if state := breaker.State(EndpointGroupPayouts); state != CircuitHalfOpen {
+		t.Errorf("State() = %v, want half-open", state)
+	}
+	if _, err := client.PayoutsAccount.Balance(ctx); errors.Is(err, apierror.ErrCircuitOpen) {
+		t.Fatalf("Balance() error = %v, want trial request", err)
+	}
+	if state := breaker.State(EndpointGroupPayouts); state != CircuitClosed {
+		t.Errorf("State() = %v, want closed", state)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"payouts:closed->open", "payouts:open->half-open", "payouts:half-open->closed"}
+	if fmt.Sprint(changes) != fmt.Sprint(want) {
+		t.Errorf("state changes = %v, want %v", changes, want)
+	}
+}
+
+func TestCircuitBreakerHalfOpenFailure(t *testing.T) {
+	breaker := NewCircuitBreaker(CircuitBreakerOptions{FailureThreshold: 1, OpenTimeout: 10 * time.Millisecond})
+	ctx := context.Background()
+	fail := errors.New("connection refused")
+
+	breaker.allow(EndpointGroupOther)
+	breaker.record(ctx, EndpointGroupOther, nil, fail)
+	time.Sleep(15 * time.Millisecond)
+
+	if err := breaker.allow(EndpointGroupOther); err != nil {
+		t.Fatalf("allow() trial error = %v", err)
+	}
+	if err := breaker.allow(EndpointGroupOther); !errors.Is(err, apierror.ErrCircuitOpen) {
+		t.Fatalf("allow() during trial error = %v, want circuit open", err)
+	}
+	breaker.record(ctx, EndpointGroupOther, nil, fail)
+	if state := breaker.State(EndpointGroupOther); state != CircuitOpen {
+		t.Errorf("State() after failed trial = %v, want open", state)
+	}
+
+	// Requests cancelled by the caller are not counted
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	time.Sleep(15 * time.Millisecond)
+	breaker.allow(EndpointGroupOther)
+	breaker.record(canceled, EndpointGroupOther, nil, context.Canceled)
+	if err := breaker.allow(EndpointGroupOther); err != nil {
+		t.Errorf("allow() after cancelled trial error = %v", err)
+	}
+}
+
+func TestCircuitBreakerHalfOpenRetryAt(t *testing.T) {
+	clock := payostest.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	breaker := NewCircuitBreaker(CircuitBreakerOptions{FailureThreshold: 1, OpenTimeout: 30 * time.Second, Clock: clock})
+	ctx := context.Background()
+
+	breaker.allow(EndpointGroupOther)
+	breaker.record(ctx, EndpointGroupOther, nil, errors.New("connection refused"))
+
+	var circuitErr *apierror.CircuitOpenError
+	if err := breaker.allow(EndpointGroupOther); !errors.As(err, &circuitErr) || !circuitErr.RetryAt.Equal(clock.Now().Add(30*time.Second)) {
+		t.Fatalf("allow() while open error = %v, want retry at the end of the open timeout", err)
+	}
+
+	clock.Advance(30 * time.Second)
+	if err := breaker.allow(EndpointGroupOther); err != nil {
+		t.Fatalf("allow() trial error = %v", err)
+	}
+	clock.Advance(time.Second)
+	if err := breaker.allow(EndpointGroupOther); !errors.As(err, &circuitErr) {
+		t.Fatalf("allow() during trial error = %v, want circuit open", err)
+	}
+	if want := clock.Now().Add(30 * time.Second); !circuitErr.RetryAt.Equal(want) {
+		t.Errorf("RetryAt during trial = %v, want %v", circuitErr.RetryAt, want)
+	}
+}
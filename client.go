@@ -7,20 +7,13 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
-	"math"
-	"math/rand"
 	"net/http"
-	"net/http/httputil"
 	"net/url"
-	"os"
-	"strconv"
-	"strings"
 	"time"
 
+	"github.com/payOSHQ/payos-lib-golang/v2/apierror"
 	"github.com/payOSHQ/payos-lib-golang/v2/internal"
-	"github.com/payOSHQ/payos-lib-golang/v2/internal/apierror"
-	"github.com/payOSHQ/payos-lib-golang/v2/internal/crypto"
+	"github.com/payOSHQ/payos-lib-golang/v2/signature"
 )
 
 const (
@@ -38,32 +31,38 @@ type RequestHandler func(ctx context.Context, req *http.Request) (*http.Response
 
 // Client is the PayOS API client
 type Client struct {
-	clientId    string
-	apiKey      string
-	checksumKey string
+	credentials CredentialsProvider
 	partnerCode string
 	baseURL     string
 	httpClient  *http.Client
 	maxRetries  int
 	timeout     time.Duration
 	middlewares []Middleware
+
+	idempotencyKeyGenerator IdempotencyKeyGenerator
+	webhookDeduper          *WebhookDeduper
+	retryPolicy             RetryPolicy
+	rateLimiter             *RateLimiter
+	metrics                 Metrics
+	observer                Observer
+	clock                   Clock
+	jitter                  JitterSource
 }
 
 // NewClient creates a new PayOS client with the provided options
 func NewClient(opts *PayOSOptions) (*Client, error) {
 	opts = NewPayOSOptions(opts)
 
-	// Validate required fields
-	if opts.ClientId == "" {
-		return nil, apierror.NewPayOSError("The PAYOS_CLIENT_ID environment variable is missing or empty; either provide it, or instantiate the PayOS client with a ClientId option.")
-	}
-	if opts.ApiKey == "" {
-		return nil, apierror.NewPayOSError("The PAYOS_API_KEY environment variable is missing or empty; either provide it, or instantiate the PayOS client with a ApiKey option.")
-	}
-	if opts.ChecksumKey == "" {
-		return nil, apierror.NewPayOSError("The PAYOS_CHECKSUM_KEY environment variable is missing or empty; either provide it, or instantiate the PayOS client with a ChecksumKey option.")
+	// The client uses the credentials of the payment channel
+	credentials, _, err := opts.channelCredentials()
+	if err != nil {
+		return nil, err
 	}
+	return newClient(opts, credentials), nil
+}
 
+// newClient creates a client of resolved options signing with credentials
+func newClient(opts *PayOSOptions, credentials CredentialsProvider) *Client {
 	// Create HTTP client if not provided
 	httpClient := opts.HTTPClient
 	if httpClient == nil {
@@ -72,25 +71,57 @@ func NewClient(opts *PayOSOptions) (*Client, error) {
 		}
 	}
 
-	// Prepare middlewares with debug logger if enabled
+	// Prepare middlewares with the logger if enabled
+	// DebugLogger is kept as a debug level Logger writing through the *log.Logger
 	middlewares := opts.Middlewares
-	if opts.DebugLogger != nil {
-		// Prepend debug logger middleware as the first (outermost) middleware
-		debugMiddleware := createDebugLoggerMiddleware(opts.DebugLogger)
-		middlewares = append([]Middleware{debugMiddleware}, middlewares...)
+	logger := opts.Logger
+	if logger == nil && opts.DebugLogger != nil {
+		logger = newDebugSlogLogger(opts.DebugLogger)
+	}
+	if logger != nil {
+		// Prepend logger middleware as the first (outermost) middleware
+		loggerMiddleware := createLoggerMiddleware(logger, opts.LoggerOptions, orSystemClock(opts.Clock))
+		middlewares = append([]Middleware{loggerMiddleware}, middlewares...)
+	}
+
+	idempotencyKeyGenerator := opts.IdempotencyKeyGenerator
+	if idempotencyKeyGenerator == nil {
+		idempotencyKeyGenerator = UUIDKeyGenerator{}
+	}
+	metrics := opts.Metrics
+	if metrics == nil {
+		metrics = nopMetrics{}
+	}
+	observer := opts.Observer
+	if observer == nil {
+		observer = nopObserver{}
 	}
 
 	return &Client{
-		clientId:    opts.ClientId,
-		apiKey:      opts.ApiKey,
-		checksumKey: opts.ChecksumKey,
+		credentials: credentials,
 		partnerCode: opts.PartnerCode,
 		baseURL:     opts.BaseURL,
 		httpClient:  httpClient,
 		maxRetries:  opts.MaxRetries,
 		timeout:     opts.Timeout,
 		middlewares: middlewares,
-	}, nil
+
+		idempotencyKeyGenerator: idempotencyKeyGenerator,
+		webhookDeduper:          opts.WebhookDeduper,
+		retryPolicy:             orDefaultRetryPolicy(opts.RetryPolicy),
+		rateLimiter:             opts.RateLimiter,
+		metrics:                 metrics,
+		observer:                observer,
+		clock:                   orSystemClock(opts.Clock),
+		jitter:                  orGlobalJitter(opts.Jitter),
+	}
+}
+
+// withCredentials returns a copy of the client signing with credentials
+func (c *Client) withCredentials(credentials CredentialsProvider) *Client {
+	clone := *c
+	clone.credentials = credentials
+	return &clone
 }
 
 // SignatureOpts contains signature options for requests and responses
@@ -116,8 +147,8 @@ func (c *Client) getUserAgent() string {
 }
 
 // buildURL constructs the full URL with query parameters
-func (c *Client) buildURL(path string, query map[string]interface{}) (string, error) {
-	baseURL, err := url.Parse(c.baseURL)
+func (c *Client) buildURL(base string, path string, query map[string]interface{}) (string, error) {
+	baseURL, err := url.Parse(base)
 	if err != nil {
 		return "", err
 	}
@@ -152,10 +183,10 @@ func (c *Client) buildURL(path string, query map[string]interface{}) (string, er
 }
 
 // buildHeaders constructs HTTP headers for the request
-func (c *Client) buildHeaders(additional map[string]string) http.Header {
+func (c *Client) buildHeaders(creds Credentials, additional map[string]string) http.Header {
 	headers := http.Header{}
-	headers.Set("x-client-id", c.clientId)
-	headers.Set("x-api-key", c.apiKey)
+	headers.Set("x-client-id", creds.ClientId)
+	headers.Set("x-api-key", creds.ApiKey)
 	headers.Set("Content-Type", "application/json")
 	headers.Set("User-Agent", c.getUserAgent())
 
@@ -171,15 +202,24 @@ func (c *Client) buildHeaders(additional map[string]string) http.Header {
 }
 
 // buildMiddlewareChain builds the middleware chain with the base HTTP handler
-func (c *Client) buildMiddlewareChain() RequestHandler {
+// Per-call middlewares run inside the client middlewares
+func (c *Client) buildMiddlewareChain(extra ...Middleware) RequestHandler {
 	// Base handler that actually executes the HTTP request
 	baseHandler := func(ctx context.Context, req *http.Request) (*http.Response, error) {
 		return c.httpClient.Do(req)
 	}
 
+	// The rate limiter runs right before the request is sent
+	handler := baseHandler
+	if c.rateLimiter != nil {
+		handler = c.rateLimiter.Middleware()(handler)
+	}
+
 	// Wrap base handler with middlewares in reverse order
 	// so the first middleware in the slice is the outermost
-	handler := baseHandler
+	for i := len(extra) - 1; i >= 0; i-- {
+		handler = extra[i](handler)
+	}
 	for i := len(c.middlewares) - 1; i >= 0; i-- {
 		handler = c.middlewares[i](handler)
 	}
@@ -187,206 +227,191 @@ func (c *Client) buildMiddlewareChain() RequestHandler {
 	return handler
 }
 
-// createDebugLoggerMiddleware creates a middleware for debug logging
-func createDebugLoggerMiddleware(logger *log.Logger) Middleware {
-	// Use default logger if nil is passed
-	if logger == nil {
-		logger = log.New(os.Stderr, "[PayOS] ", log.LstdFlags)
+// Request makes an HTTP request with retry logic
+// The response data is decoded into generic maps and slices; use Do for typed results
+func (c *Client) Request(ctx context.Context, opts *RequestOptions, reqOpts ...RequestOption) (any, error) {
+	data, err := c.requestRaw(ctx, opts, reqOpts)
+	if err != nil {
+		return nil, err
 	}
 
-	return func(next RequestHandler) RequestHandler {
-		return func(ctx context.Context, req *http.Request) (*http.Response, error) {
-			start := time.Now()
-
-			// Dump request
-			requestDump, err := httputil.DumpRequestOut(req, true)
-			if err != nil {
-				logger.Printf("Failed to dump request: %v", err)
-			} else {
-				logger.Printf("Request:\n%s", string(requestDump))
-			}
-
-			// Execute request
-			resp, err := next(ctx, req)
-
-			duration := time.Since(start)
-
-			if err != nil {
-				logger.Printf("Request failed after %v: %v", duration, err)
-				return resp, err
-			}
-
-			// Dump response
-			responseDump, dumpErr := httputil.DumpResponse(resp, true)
-			if dumpErr != nil {
-				logger.Printf("Response: %d %s in %v (failed to dump body: %v)",
-					resp.StatusCode, http.StatusText(resp.StatusCode), duration, dumpErr)
-			} else {
-				logger.Printf("Response (in %v):\n%s", duration, string(responseDump))
-			}
-
-			return resp, nil
-		}
+	var result any
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, apierror.NewPayOSError("failed to parse response")
 	}
+	return result, nil
 }
 
-// shouldRetry determines if a request should be retried based on the response
-func (c *Client) shouldRetry(statusCode int) bool {
-	return statusCode == 408 || statusCode == 429 || statusCode >= 500
-}
-
-// calculateBackoff calculates the backoff duration for retry attempts
-func (c *Client) calculateBackoff(attempt int, headers http.Header) time.Duration {
-	// Check for Retry-After header
-	if retryAfter := headers.Get("Retry-After"); retryAfter != "" {
-		if seconds, err := strconv.ParseFloat(retryAfter, 64); err == nil {
-			return time.Duration(seconds * float64(time.Second))
-		}
-		if retryTime, err := time.Parse(time.RFC1123, retryAfter); err == nil {
-			duration := time.Until(retryTime)
-			if duration > 0 && duration < 60*time.Second {
-				return duration
-			}
-		}
+// Do makes an HTTP request with retry logic and decodes the response data into T
+// The data field is decoded straight from the raw response bytes, so numbers keep their precision
+//
+//	payout, err := payos.Do[payos.Payout](ctx, client.Client, &payos.RequestOptions{
+//	    Method:        http.MethodGet,
+//	    Path:          "/v1/payouts/" + payoutId,
+//	    SignatureOpts: &payos.SignatureOpts{Response: "header"},
+//	})
+func Do[T any](ctx context.Context, c *Client, opts *RequestOptions, reqOpts ...RequestOption) (*T, error) {
+	data, err := c.requestRaw(ctx, opts, reqOpts)
+	if err != nil {
+		return nil, err
 	}
 
-	// Check for X-RateLimit-Reset header
-	if rateLimitReset := headers.Get("X-RateLimit-Reset"); rateLimitReset != "" {
-		if timestamp, err := strconv.ParseFloat(rateLimitReset, 64); err == nil {
-			duration := time.Until(time.Unix(int64(timestamp), 0))
-			if duration > 0 && duration < 60*time.Second {
-				return duration
-			}
-		}
+	var result T
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, apierror.NewPayOSError("failed to parse response")
 	}
-
-	// Exponential backoff with jitter
-	const (
-		initRetryDelay = 0.5  // seconds
-		maxRetryDelay  = 10.0 // seconds
-	)
-
-	sleepSeconds := math.Min(initRetryDelay*math.Pow(2, float64(attempt)), maxRetryDelay)
-	jitter := 1 - rand.Float64()*0.25 // 75% to 100%
-	return time.Duration(sleepSeconds * jitter * float64(time.Second))
+	return &result, nil
 }
 
-// Request makes an HTTP request with retry logic
-func (c *Client) Request(ctx context.Context, opts *RequestOptions) (any, error) {
+// requestRaw makes an HTTP request with retry logic and returns the raw response data
+func (c *Client) requestRaw(ctx context.Context, opts *RequestOptions, reqOpts []RequestOption) (json.RawMessage, error) {
 	if opts == nil {
 		return nil, errors.New("request options cannot be nil")
 	}
 
+	cfg := c.newRequestConfig(reqOpts)
+	start := c.clock.Now()
+	defer func() { cfg.captureLatency(c.clock.Now().Sub(start)) }()
+	if cfg.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.timeout)
+		defer cancel()
+	}
+	ctx, span := c.startOperationSpan(ctx, cfg, opts.Method, opts.Path)
+
 	var lastErr error
-	maxAttempts := c.maxRetries + 1
+	var attempts []apierror.Attempt
+	maxAttempts := cfg.maxRetries + 1
+	idempotencyKey := cfg.requestIdempotencyKey(opts.Headers)
 
 	for attempt := 0; attempt < maxAttempts; attempt++ {
-		result, err := c.executeRequest(ctx, opts, attempt)
+		result, err := c.executeRequest(ctx, opts, cfg, attempt)
 		if err == nil {
+			observeRetrySuccess(ctx, cfg.retryPolicy)
+			c.observeRequest(ctx, cfg, opts.Method, opts.Path, attempt+1, start, nil)
+			endOperationSpan(span, attempt+1, nil)
 			return result, nil
 		}
 
 		lastErr = err
+		attempts = append(attempts, newAttempt(err))
 
 		// Check if we should retry
-		if attempt >= c.maxRetries {
+		if attempt >= cfg.maxRetries {
 			break
 		}
 
-		// Determine if error is retryable
-		shouldRetry := false
-		var backoffHeaders http.Header
-
-		// Check for specific retryable error types
-		var tooManyReqErr *apierror.TooManyRequestError
-		var internalServerErr *apierror.InternalServerError
-		var apiErr *apierror.APIError
-
-		if errors.As(err, &tooManyReqErr) {
-			shouldRetry = true
-			backoffHeaders = tooManyReqErr.Headers
-		} else if errors.As(err, &internalServerErr) {
-			shouldRetry = true
-			backoffHeaders = internalServerErr.Headers
-		} else if errors.As(err, &apiErr) {
-			shouldRetry = c.shouldRetry(apiErr.StatusCode)
-			backoffHeaders = apiErr.Headers
-		} else {
-			// Network errors are retryable
-			var connErr *apierror.ConnectionError
-			var timeoutErr *apierror.ConnectionTimeoutError
-			if errors.As(err, &connErr) || errors.As(err, &timeoutErr) {
-				shouldRetry = true
-			}
-		}
-
+		// Ask the retry policy whether and when to retry
+		backoff, shouldRetry := c.retryDelay(ctx, cfg, &RetryAttempt{
+			Method:         opts.Method,
+			Path:           opts.Path,
+			IdempotencyKey: idempotencyKey,
+			Attempt:        attempt,
+			Err:            err,
+		})
 		if !shouldRetry {
 			break
 		}
 
-		// Calculate backoff
-		backoff := c.calculateBackoff(attempt, backoffHeaders)
-
 		// Wait before retry
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case <-time.After(backoff):
-			// Continue to next attempt
+		attempts[len(attempts)-1].Backoff = backoff
+		retryEvent(span, attempt, backoff)
+		if err := sleepContext(ctx, c.clock, backoff); err != nil {
+			lastErr = err
+			break
 		}
 	}
 
-	return nil, lastErr
+	err := cfg.operationError(opts.Method, opts.Path, idempotencyKey, attempts, lastErr)
+	c.observeRequest(ctx, cfg, opts.Method, opts.Path, len(attempts), start, err)
+	endOperationSpan(span, len(attempts), err)
+	return nil, err
+}
+
+// handlerError converts an error returned by the middleware chain
+// Circuit breaker errors are kept as is so they are not retried
+func handlerError(ctx context.Context, err error) error {
+	var circuitErr *apierror.CircuitOpenError
+	if errors.As(err, &circuitErr) {
+		return circuitErr
+	}
+	if ctx.Err() != nil {
+		return apierror.NewConnectionTimeoutError("request cancelled or timed out")
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &apierror.ConnectionTimeoutError{Message: "request cannot complete before the deadline", Err: err}
+	}
+	return apierror.NewConnectionError("request failed", err)
 }
 
-// executeRequest performs a single HTTP request
-func (c *Client) executeRequest(ctx context.Context, opts *RequestOptions, attempt int) (any, error) {
+// executeRequest performs a single HTTP request and returns the raw response data
+func (c *Client) executeRequest(ctx context.Context, opts *RequestOptions, cfg *requestConfig, attempt int) (data json.RawMessage, err error) {
+	ctx = withAttempt(ctx, attempt)
+	ctx, span, timings := c.startAttemptSpan(ctx, opts.Method, opts.Path, attempt)
+
+	// Record the attempt in the metrics and trace once it is done
+	var statusCode int
+	var code string
+	var signatureChecked bool
+	defer func(start time.Time) {
+		c.observeAttempt(ctx, cfg, AttemptMetrics{
+			Method:     opts.Method,
+			Path:       opts.Path,
+			Attempt:    attempt,
+			StatusCode: statusCode,
+			Code:       code,
+			Err:        err,
+		}, start)
+		if signatureChecked {
+			span.SetAttributes(Attribute{Key: "payos.signature.valid", Value: err == nil})
+		}
+		endAttemptSpan(span, timings, statusCode, code, err)
+	}(c.clock.Now())
+
 	// Build URL
-	fullURL, err := c.buildURL(opts.Path, opts.Query)
+	fullURL, err := c.buildURL(cfg.baseURL, opts.Path, opts.Query)
 	if err != nil {
 		return nil, apierror.NewConnectionError("failed to build URL", err)
 	}
 
+	// Credentials are loaded for every attempt so rotated keys are used at once
+	creds, err := c.loadCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	// Prepare request body
 	var bodyReader io.Reader
 	var bodyData interface{} = opts.Body
 
 	// Handle signature for request
 	if opts.SignatureOpts != nil && opts.SignatureOpts.Request != "" && opts.Body != nil {
-		switch opts.SignatureOpts.Request {
-		case "create-payment-link":
-			signature, err := crypto.CreateSignatureOfPaymentRequest(opts.Body, c.checksumKey)
-			if err != nil {
-				return nil, apierror.NewInvalidSignatureError("failed to create payment signature")
-			}
+		sig, err := signature.Sign(signature.Scheme(opts.SignatureOpts.Request), creds.ChecksumKey, opts.Body)
+		if err != nil {
+			return nil, &apierror.InvalidSignatureError{Message: fmt.Sprintf("failed to create %s signature", opts.SignatureOpts.Request), Err: err}
+		}
+		switch signature.Scheme(opts.SignatureOpts.Request) {
+		case signature.SchemePaymentRequest:
 			// Add signature to the body
 			// Since CreatePaymentLinkRequest is an alias, we can use it directly
 			if req, ok := opts.Body.(CreatePaymentLinkRequest); ok {
-				req.Signature = &signature
+				req.Signature = &sig
 				bodyData = req
 			} else if bodyMap, ok := opts.Body.(map[string]interface{}); ok {
-				bodyMap["signature"] = signature
+				bodyMap["signature"] = sig
 				bodyData = bodyMap
 			}
-		case "body":
-			signature, err := crypto.CreateSignatureFromObj(opts.Body, c.checksumKey)
-			if err != nil {
-				return nil, apierror.NewInvalidSignatureError("failed to create body signature")
-			}
+		case signature.SchemeBody:
 			// Add signature to body
 			if bodyMap, ok := opts.Body.(map[string]interface{}); ok {
-				bodyMap["signature"] = signature
+				bodyMap["signature"] = sig
 				bodyData = bodyMap
 			}
-		case "header":
-			signature, err := crypto.CreateSignature(c.checksumKey, opts.Body, nil)
-			if err != nil {
-				return nil, apierror.NewInvalidSignatureError("failed to create header signature")
-			}
+		case signature.SchemeHeader:
 			if opts.Headers == nil {
 				opts.Headers = make(map[string]string)
 			}
-			opts.Headers["x-signature"] = signature
+			opts.Headers["x-signature"] = sig
 		}
 	}
 
@@ -406,24 +431,26 @@ func (c *Client) executeRequest(ctx context.Context, opts *RequestOptions, attem
 	}
 
 	// Set headers
-	req.Header = c.buildHeaders(opts.Headers)
+	req.Header = c.buildHeaders(creds, opts.Headers)
+	cfg.applyHeaders(req.Header)
+	injectTraceParent(req.Header, span)
 
 	// Build and execute middleware chain
-	handler := c.buildMiddlewareChain()
+	handler := c.buildMiddlewareChain(cfg.middlewares...)
 	resp, err := handler(ctx, req)
 	if err != nil {
-		if ctx.Err() != nil {
-			return nil, apierror.NewConnectionTimeoutError("request cancelled or timed out")
-		}
-		return nil, apierror.NewConnectionError("request failed", err)
+		return nil, handlerError(ctx, err)
 	}
 	defer resp.Body.Close()
+	statusCode = resp.StatusCode
 
 	// Read response body
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, apierror.NewConnectionError("failed to read response body", err)
 	}
+	cfg.captureResponse(resp, respBody)
+	cfg.captureRaw(resp, respBody, attempt)
 
 	// Only parse response for 200 status code
 	if resp.StatusCode != http.StatusOK {
@@ -436,51 +463,44 @@ func (c *Client) executeRequest(ctx context.Context, opts *RequestOptions, attem
 		} else {
 			errDesc = string(respBody)
 		}
+		code = errCode
 		return nil, apierror.GenerateError(resp.StatusCode, errCode, errDesc, resp.Header)
 	}
 
-	// Parse response
-	var apiResp PayOSResponseType
+	// Parse response, keeping data as raw bytes for signature verification and decoding
+	var apiResp rawResponse
 	if err := json.Unmarshal(respBody, &apiResp); err != nil {
 		return nil, apierror.NewPayOSError("failed to parse response")
 	}
+	code = apiResp.Code
 
 	// Check response status
-	if apiResp.Code != "00" || apiResp.Data == nil {
+	if apiResp.Code != "00" || len(apiResp.Data) == 0 || string(apiResp.Data) == "null" {
 		return nil, apierror.GenerateError(resp.StatusCode, apiResp.Code, apiResp.Desc, resp.Header)
 	}
 
 	// Verify response signature if required
 	if opts.SignatureOpts != nil && opts.SignatureOpts.Response != "" {
+		signatureChecked = true
 		var receivedSignature string
-		var expectedSignature string
-		var err error
 
-		switch opts.SignatureOpts.Response {
-		case "body":
+		switch signature.Scheme(opts.SignatureOpts.Response) {
+		case signature.SchemeBody:
 			if apiResp.Signature == nil {
 				return nil, apierror.NewInvalidSignatureError("response signature not found in body")
 			}
 			receivedSignature = *apiResp.Signature
-			expectedSignature, err = crypto.CreateSignatureFromObj(apiResp.Data, c.checksumKey)
-			if err != nil {
-				return nil, apierror.NewInvalidSignatureError("failed to create response signature")
-			}
-		case "header":
+		case signature.SchemeHeader:
 			receivedSignature = resp.Header.Get("x-signature")
 			if receivedSignature == "" {
 				return nil, apierror.NewInvalidSignatureError("response signature not found in header")
 			}
-			expectedSignature, err = crypto.CreateSignature(c.checksumKey, apiResp.Data, nil)
-			if err != nil {
-				return nil, apierror.NewInvalidSignatureError("failed to create response signature")
-			}
 		default:
 			return nil, apierror.NewInvalidSignatureError("invalid signature response type")
 		}
 
-		if receivedSignature != expectedSignature {
-			return nil, apierror.NewInvalidSignatureError("data integrity check failed")
+		if err := creds.verify(signature.Scheme(opts.SignatureOpts.Response), apiResp.Data, receivedSignature); err != nil {
+			return nil, &apierror.InvalidSignatureError{Message: "data integrity check failed", Err: err}
 		}
 	}
 
@@ -488,153 +508,66 @@ func (c *Client) executeRequest(ctx context.Context, opts *RequestOptions, attem
 }
 
 // Get performs a GET request
-func (c *Client) Get(ctx context.Context, path string, query map[string]interface{}, headers map[string]string, signatureOpts *SignatureOpts) (interface{}, error) {
+func (c *Client) Get(ctx context.Context, path string, query map[string]interface{}, headers map[string]string, signatureOpts *SignatureOpts, reqOpts ...RequestOption) (interface{}, error) {
 	return c.Request(ctx, &RequestOptions{
 		Method:        "GET",
 		Path:          path,
 		Query:         query,
 		Headers:       headers,
 		SignatureOpts: signatureOpts,
-	})
+	}, reqOpts...)
 }
 
 // Post performs a POST request
-func (c *Client) Post(ctx context.Context, path string, body interface{}, signatureOpts *SignatureOpts, headers map[string]string) (interface{}, error) {
+func (c *Client) Post(ctx context.Context, path string, body interface{}, signatureOpts *SignatureOpts, headers map[string]string, reqOpts ...RequestOption) (interface{}, error) {
 	return c.Request(ctx, &RequestOptions{
 		Method:        "POST",
 		Path:          path,
 		Body:          body,
 		SignatureOpts: signatureOpts,
 		Headers:       headers,
-	})
+	}, reqOpts...)
 }
 
 // Put performs a PUT request
-func (c *Client) Put(ctx context.Context, path string, body interface{}, signatureOpts *SignatureOpts, headers map[string]string) (interface{}, error) {
+func (c *Client) Put(ctx context.Context, path string, body interface{}, signatureOpts *SignatureOpts, headers map[string]string, reqOpts ...RequestOption) (interface{}, error) {
 	return c.Request(ctx, &RequestOptions{
 		Method:        "PUT",
 		Path:          path,
 		Body:          body,
 		SignatureOpts: signatureOpts,
 		Headers:       headers,
-	})
+	}, reqOpts...)
 }
 
 // Delete performs a DELETE request
-func (c *Client) Delete(ctx context.Context, path string, query map[string]interface{}, headers map[string]string, signatureOpts *SignatureOpts) (interface{}, error) {
+func (c *Client) Delete(ctx context.Context, path string, query map[string]interface{}, headers map[string]string, signatureOpts *SignatureOpts, reqOpts ...RequestOption) (interface{}, error) {
 	return c.Request(ctx, &RequestOptions{
 		Method:        "DELETE",
 		Path:          path,
 		Query:         query,
 		Headers:       headers,
 		SignatureOpts: signatureOpts,
-	})
+	}, reqOpts...)
 }
 
-// DownloadFile downloads a file from the API
-func (c *Client) DownloadFile(ctx context.Context, path string) (*FileDownloadResponse, error) {
-	// Build URL
-	fullURL, err := c.buildURL(path, nil)
+// DownloadFile downloads a file from the API into memory
+func (c *Client) DownloadFile(ctx context.Context, path string, reqOpts ...RequestOption) (*FileDownloadResponse, error) {
+	stream, err := c.DownloadStream(ctx, path, reqOpts...)
 	if err != nil {
-		return nil, apierror.NewConnectionError("failed to build URL", err)
+		return nil, err
 	}
+	defer stream.Close()
 
-	// Create request
-	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
+	data, err := io.ReadAll(stream)
 	if err != nil {
-		return nil, apierror.NewConnectionError("failed to create request", err)
-	}
-
-	// Set headers
-	req.Header = c.buildHeaders(nil)
-
-	// Execute request with retry logic
-	var lastErr error
-	maxAttempts := c.maxRetries + 1
-
-	for attempt := 0; attempt < maxAttempts; attempt++ {
-		resp, err := c.httpClient.Do(req)
-		if err != nil {
-			lastErr = apierror.NewConnectionError("request failed", err)
-			if attempt < c.maxRetries {
-				time.Sleep(c.calculateBackoff(attempt, nil))
-				continue
-			}
-			return nil, lastErr
-		}
-
-		// Check if response is successful
-		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			// Check if it's a JSON error response
-			contentType := resp.Header.Get("Content-Type")
-			if contentType != "" && bytes.Contains([]byte(contentType), []byte("application/json")) {
-				defer resp.Body.Close()
-				respBody, _ := io.ReadAll(resp.Body)
-				var apiResp PayOSResponseType
-				if err := json.Unmarshal(respBody, &apiResp); err == nil {
-					return nil, apierror.GenerateError(resp.StatusCode, apiResp.Code, apiResp.Desc, resp.Header)
-				}
-			}
-
-			// Read file data
-			defer resp.Body.Close()
-			data, err := io.ReadAll(resp.Body)
-			if err != nil {
-				return nil, apierror.NewConnectionError("failed to read file data", err)
-			}
-
-			// Extract filename from Content-Disposition header
-			var filename *string
-			contentDisposition := resp.Header.Get("Content-Disposition")
-			if contentDisposition != "" {
-				// Simple filename extraction - in production, use more robust parsing
-				if idx := strings.Index(contentDisposition, "filename="); idx >= 0 {
-					fnameStr := contentDisposition[idx+9:]
-					fnameStr = strings.Trim(fnameStr, "\"")
-					filename = &fnameStr
-				}
-			}
-
-			// Get content type
-			contentTypeVal := resp.Header.Get("Content-Type")
-			if contentTypeVal == "" {
-				contentTypeVal = "application/octet-stream"
-			}
-
-			// Get content length
-			var size *int64
-			if contentLength := resp.Header.Get("Content-Length"); contentLength != "" {
-				if length, err := strconv.ParseInt(contentLength, 10, 64); err == nil {
-					size = &length
-				}
-			}
-
-			return &FileDownloadResponse{
-				Filename:    filename,
-				ContentType: contentTypeVal,
-				Size:        size,
-				Data:        data,
-			}, nil
-		}
-
-		// Handle error responses
-		defer resp.Body.Close()
-		respBody, _ := io.ReadAll(resp.Body)
-		var apiResp PayOSResponseType
-		if err := json.Unmarshal(respBody, &apiResp); err == nil {
-			lastErr = apierror.GenerateError(resp.StatusCode, apiResp.Code, apiResp.Desc, resp.Header)
-		} else {
-			lastErr = apierror.GenerateError(resp.StatusCode, "", string(respBody), resp.Header)
-		}
-
-		// Check if we should retry
-		if c.shouldRetry(resp.StatusCode) && attempt < c.maxRetries {
-			time.Sleep(c.calculateBackoff(attempt, resp.Header))
-			continue
-		}
-
-		return nil, lastErr
+		return nil, err
 	}
 
-	return nil, lastErr
+	return &FileDownloadResponse{
+		Filename:    stream.Filename,
+		ContentType: stream.ContentType,
+		Size:        stream.Size,
+		Data:        data,
+	}, nil
 }
@@ -1,13 +1,282 @@
 package payos
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/payOSHQ/payos-lib-golang/v2/apierror"
+	"github.com/payOSHQ/payos-lib-golang/v2/signature"
+)
 
 func TestClientConfiguration(t *testing.T) {
-	// TODO: implement test
-	t.Skip("Test implementation pending")
+	var header http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"code":"00","desc":"success","data":{"ok":true}}`))
+	}))
+	defer srv.Close()
+
+	httpClient := &http.Client{Timeout: 5 * time.Second}
+	client, err := NewClient(&PayOSOptions{
+		ClientId:    "client-id",
+		ApiKey:      "api-key",
+		ChecksumKey: "checksum-key",
+		PartnerCode: "partner",
+		BaseURL:     srv.URL,
+		HTTPClient:  httpClient,
+		MaxRetries:  5,
+		Timeout:     10 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if client.baseURL != srv.URL || client.httpClient != httpClient || client.maxRetries != 5 || client.timeout != 10*time.Second {
+		t.Errorf("client = baseURL %s, maxRetries %d, timeout %v", client.baseURL, client.maxRetries, client.timeout)
+	}
+
+	if _, err := client.Request(context.Background(), &RequestOptions{Method: http.MethodGet, Path: "/v2/payment-requests/1"}); err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+	for key, want := range map[string]string{
+		"x-client-id":    "client-id",
+		"x-api-key":      "api-key",
+		"x-partner-code": "partner",
+		"Content-Type":   "application/json",
+		"User-Agent":     client.getUserAgent(),
+	} {
+		if got := header.Get(key); got != want {
+			t.Errorf("header %s = %q, want %q", key, got, want)
+		}
+	}
 }
 
 func TestNewClient(t *testing.T) {
-	// TODO: implement test
-	t.Skip("Test implementation pending")
+	for _, name := range []string{"PAYOS_CLIENT_ID", "PAYOS_API_KEY", "PAYOS_CHECKSUM_KEY", "PAYOS_PARTNER_CODE", "PAYOS_BASE_URL"} {
+		t.Setenv(name, "")
+	}
+
+	tests := []struct {
+		name    string
+		opts    *PayOSOptions
+		wantErr bool
+	}{
+		{"nil options", nil, true},
+		{"missing client ID", &PayOSOptions{ApiKey: "api-key", ChecksumKey: "checksum-key"}, true},
+		{"missing API key", &PayOSOptions{ClientId: "client-id", ChecksumKey: "checksum-key"}, true},
+		{"missing checksum key", &PayOSOptions{ClientId: "client-id", ApiKey: "api-key"}, true},
+		{"credentials", &PayOSOptions{ClientId: "client-id", ApiKey: "api-key", ChecksumKey: "checksum-key"}, false},
+		{"credentials provider", &PayOSOptions{CredentialsProvider: StaticCredentials(Credentials{ClientId: "client-id", ApiKey: "api-key", ChecksumKey: "checksum-key"})}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(tt.opts)
+			if tt.wantErr {
+				var payosErr *apierror.PayOSError
+				if !errors.As(err, &payosErr) || client != nil {
+					t.Errorf("NewClient() = %v, %v, want a PayOSError", client, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+			if client.baseURL != PayOSBaseUrl || client.maxRetries != defaultMaxRetry || client.timeout != defaultTimeout {
+				t.Errorf("defaults = baseURL %s, maxRetries %d, timeout %v", client.baseURL, client.maxRetries, client.timeout)
+			}
+			if client.httpClient == nil || client.httpClient.Timeout != defaultTimeout {
+				t.Errorf("default HTTP client = %+v", client.httpClient)
+			}
+			creds, err := client.loadCredentials(context.Background())
+			if err != nil || creds.ClientId != "client-id" {
+				t.Errorf("loadCredentials() = %+v, %v", creds, err)
+			}
+		})
+	}
+}
+
+func TestDo(t *testing.T) {
+	client, _ := newTestPayOS(t)
+	ctx := context.Background()
+
+	payout, err := client.Payouts.Create(ctx, testPayoutRequest("ref-do"), nil)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	got, err := Do[Payout](ctx, client.Client, &RequestOptions{
+		Method:        "GET",
+		Path:          "/v1/payouts/" + payout.Id,
+		SignatureOpts: &SignatureOpts{Response: "header"},
+	})
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if got.Id != payout.Id || got.Transactions[0].Amount != 50000 {
+		t.Errorf("Do() = %+v", got)
+	}
+
+	result, err := client.Client.Get(ctx, "/v1/payouts/"+payout.Id, nil, nil, &SignatureOpts{Response: "header"})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if data, ok := result.(map[string]interface{}); !ok || data["id"] != payout.Id {
+		t.Errorf("Get() = %v", result)
+	}
+}
+
+func TestRequestOptions(t *testing.T) {
+	client, _ := newTestPayOS(t)
+	ctx := context.Background()
+
+	var seen http.Header
+	record := func(next RequestHandler) RequestHandler {
+		return func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			seen = req.Header.Clone()
+			return next(ctx, req)
+		}
+	}
+
+	var resp http.Response
+	first, err := client.Payouts.Create(ctx, testPayoutRequest("ref-opts"), nil,
+		WithIdempotencyKey("idem-opts"),
+		WithHeader("x-request-source", "checkout"),
+		WithMiddleware(record),
+		WithResponseInto(&resp),
+	)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if seen.Get("x-idempotency-key") != "idem-opts" || seen.Get("x-request-source") != "checkout" {
+		t.Errorf("request headers = %v", seen)
+	}
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("x-signature") == "" {
+		t.Errorf("WithResponseInto() status = %d, header = %v", resp.StatusCode, resp.Header)
+	}
+
+	second, err := client.Payouts.Create(ctx, testPayoutRequest("ref-opts"), nil, WithIdempotencyKey("idem-opts"))
+	if err != nil {
+		t.Fatalf("Create() replay error = %v", err)
+	}
+	if second.Id != first.Id {
+		t.Errorf("replayed payout id = %s, want %s", second.Id, first.Id)
+	}
+}
+
+func TestRequestOptionsRetries(t *testing.T) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	client, _ := newTestPayOS(t)
+	ctx := context.Background()
+
+	_, err := client.PayoutsAccount.Balance(ctx, WithBaseURL(srv.URL), WithMaxRetries(0))
+	if err == nil {
+		t.Fatal("Balance() expected error")
+	}
+	if got := atomic.LoadInt32(&attempts); got != 1 {
+		t.Errorf("attempts = %d, want 1", got)
+	}
+
+	// The backoff would outlast the timeout, so the call stops after the first attempt
+	atomic.StoreInt32(&attempts, 0)
+	start := time.Now()
+	_, err = client.PayoutsAccount.Balance(ctx, WithBaseURL(srv.URL), WithMaxRetries(5), WithTimeout(50*time.Millisecond))
+	if !errors.Is(err, apierror.ErrInternalServer) {
+		t.Errorf("Balance() error = %v, want internal server error", err)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 1 || time.Since(start) >= 50*time.Millisecond {
+		t.Errorf("attempts = %d after %v, want 1 without waiting for the deadline", got, time.Since(start))
+	}
+}
+
+func TestWithRawResponse(t *testing.T) {
+	client, srv := newTestPayOS(t)
+	ctx := context.Background()
+
+	var raw RawResponse
+	payout, err := client.Payouts.Create(ctx, testPayoutRequest("ref-raw"), nil, WithRawResponse(&raw))
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if raw.StatusCode != http.StatusOK || raw.Code != "00" || raw.Retries != 0 || raw.Latency <= 0 {
+		t.Errorf("RawResponse = %+v", raw)
+	}
+	if raw.Signature == "" || raw.Signature != raw.Header.Get("x-signature") {
+		t.Errorf("RawResponse.Signature = %q", raw.Signature)
+	}
+	if err := signature.Verify(signature.SchemeHeader, srv.ChecksumKey, raw.Data, raw.Signature); err != nil {
+		t.Errorf("signed payload does not verify: %v", err)
+	}
+	if !strings.Contains(string(raw.Data), payout.Id) || !strings.Contains(string(raw.Body), string(raw.Data)) {
+		t.Errorf("RawResponse.Data = %s", raw.Data)
+	}
+
+	raw = RawResponse{}
+	if _, err := client.Payouts.Get(ctx, "missing", WithRawResponse(&raw)); err == nil {
+		t.Fatal("Get() expected error")
+	}
+	if raw.StatusCode == http.StatusOK || raw.Code == "" || raw.Desc == "" {
+		t.Errorf("RawResponse on error = %+v", raw)
+	}
+}
+
+func TestOperationError(t *testing.T) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request-Id", "req-123")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"code":"20","desc":"internal error"}`))
+	}))
+	defer srv.Close()
+
+	client, _ := newTestPayOS(t)
+	_, err := client.Payouts.Create(context.Background(), testPayoutRequest("ref-op"), nil,
+		WithBaseURL(srv.URL), WithMaxRetries(2), WithIdempotencyKey("key-1"))
+
+	var opErr *apierror.OperationError
+	if !errors.As(err, &opErr) {
+		t.Fatalf("Create() error = %v, want *apierror.OperationError", err)
+	}
+	if opErr.Op != "Payouts.Create" || opErr.Method != "POST" || opErr.Path != "/v1/payouts/" {
+		t.Errorf("operation = %s %s %s", opErr.Op, opErr.Method, opErr.Path)
+	}
+	if opErr.IdempotencyKey != "key-1" || opErr.RequestID != "req-123" {
+		t.Errorf("IdempotencyKey = %q, RequestID = %q", opErr.IdempotencyKey, opErr.RequestID)
+	}
+	if len(opErr.Attempts) != 3 || int(atomic.LoadInt32(&attempts)) != 3 {
+		t.Fatalf("attempts = %d, want 3", len(opErr.Attempts))
+	}
+	for i, attempt := range opErr.Attempts {
+		if attempt.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("attempt %d status = %d", i, attempt.StatusCode)
+		}
+		if last := i == len(opErr.Attempts)-1; last != (attempt.Backoff == 0) {
+			t.Errorf("attempt %d backoff = %v", i, attempt.Backoff)
+		}
+	}
+	if !errors.Is(err, apierror.ErrInternalServer) {
+		t.Errorf("errors.Is(err, ErrInternalServer) = false for %v", err)
+	}
+	var apiErr *apierror.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != apierror.CodeInternalError {
+		t.Errorf("errors.As(err, *APIError) = %v", apiErr)
+	}
+
+	_, err = client.PayoutsAccount.Balance(context.Background(), WithBaseURL(srv.URL), WithMaxRetries(0), WithOperation("balance-check"))
+	if !errors.As(err, &opErr) || opErr.Op != "balance-check" || len(opErr.Attempts) != 1 {
+		t.Errorf("Balance() error = %v", err)
+	}
 }
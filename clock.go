@@ -0,0 +1,84 @@
+package payos
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Clock tells the time and waits, see PayOSOptions.Clock
+// Tests can use payostest.FakeClock to run retries and backoffs without waiting
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	Sleep(d time.Duration)
+}
+
+// SystemClock is the Clock of the time package
+type SystemClock struct{}
+
+// Now returns time.Now()
+func (SystemClock) Now() time.Time { return time.Now() }
+
+// After returns time.After(d)
+func (SystemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Sleep calls time.Sleep(d)
+func (SystemClock) Sleep(d time.Duration) { time.Sleep(d) }
+
+// JitterSource returns random numbers in [0, 1) spreading out retry backoffs, see PayOSOptions.Jitter
+// Implementations must be safe for concurrent use
+type JitterSource interface {
+	Float64() float64
+}
+
+// NewJitterSource returns a JitterSource seeded with seed, so the backoffs of a test are reproducible
+func NewJitterSource(seed int64) JitterSource {
+	return &seededJitter{rng: rand.New(rand.NewSource(seed))}
+}
+
+type seededJitter struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+func (j *seededJitter) Float64() float64 {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.rng.Float64()
+}
+
+// globalJitter draws from the global math/rand source
+type globalJitter struct{}
+
+func (globalJitter) Float64() float64 { return rand.Float64() }
+
+// orSystemClock returns clock, or SystemClock when clock is nil
+func orSystemClock(clock Clock) Clock {
+	if clock == nil {
+		return SystemClock{}
+	}
+	return clock
+}
+
+// orGlobalJitter returns jitter, or the global math/rand source when jitter is nil
+func orGlobalJitter(jitter JitterSource) JitterSource {
+	if jitter == nil {
+		return globalJitter{}
+	}
+	return jitter
+}
+
+// sleepContext waits for d on clock or until ctx is done
+func sleepContext(ctx context.Context, clock Clock, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-clock.After(d):
+		return nil
+	}
+}
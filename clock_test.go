@@ -0,0 +1,126 @@
+package payos
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/payOSHQ/payos-lib-golang/v2/apierror"
+	"github.com/payOSHQ/payos-lib-golang/v2/payostest"
+)
+
+func TestClockDrivesRetries(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1)%3 != 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"code":"00","desc":"success","data":{}}`))
+	}))
+	defer srv.Close()
+
+	run := func() ([]time.Duration, time.Duration) {
+		clock := payostest.NewFakeClock(time.Now())
+		clock.SetAutoAdvance(true)
+		client, err := NewClient(&PayOSOptions{
+			ClientId:    "client-id",
+			ApiKey:      "api-key",
+			ChecksumKey: "checksum-key",
+			BaseURL:     srv.URL,
+			Clock:       clock,
+			Jitter:      NewJitterSource(42),
+			RetryPolicy: DefaultRetryPolicy{InitialBackoff: 10 * time.Second, MaxBackoff: time.Minute},
+		})
+		if err != nil {
+			t.Fatalf("NewClient() error = %v", err)
+		}
+
+		var raw RawResponse
+		start := time.Now()
+		if _, err := client.Request(context.Background(), &RequestOptions{Method: http.MethodGet, Path: "/v1/test"}, WithRawResponse(&raw)); err != nil {
+			t.Fatalf("Request() error = %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("Request() took %v with a fake clock", elapsed)
+		}
+		return clock.Sleeps(), raw.Latency
+	}
+
+	first, latency := run()
+	if len(first) != 2 || first[0] < 7500*time.Millisecond || first[1] < 15*time.Second {
+		t.Fatalf("backoffs = %v, want 2 backoffs of about 10s and 20s", first)
+	}
+	if latency != first[0]+first[1] {
+		t.Errorf("latency = %v, want the %v spent in backoffs", latency, first[0]+first[1])
+	}
+	if second, _ := run(); !reflect.DeepEqual(first, second) {
+		t.Errorf("backoffs = %v, then %v with the same jitter seed", first, second)
+	}
+}
+
+func TestFakeClockContextDeadline(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"code":"00","desc":"success","data":{}}`))
+	}))
+	defer srv.Close()
+
+	// A fake clock far from the wall clock does not change how much of the deadline is left
+	clock := payostest.NewFakeClock(time.Now().Add(24 * time.Hour))
+	clock.SetAutoAdvance(true)
+	client, err := NewClient(&PayOSOptions{
+		ClientId:    "client-id",
+		ApiKey:      "api-key",
+		ChecksumKey: "checksum-key",
+		BaseURL:     srv.URL,
+		Clock:       clock,
+		RetryPolicy: DefaultRetryPolicy{InitialBackoff: time.Second, MaxBackoff: time.Second},
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if _, err := client.Request(ctx, &RequestOptions{Method: http.MethodGet, Path: "/v1/test"}); err != nil {
+		t.Errorf("Request() error = %v, want a retry within the deadline", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("server received %d requests, want 2", calls.Load())
+	}
+}
+
+func TestRetryAfterDateUsesClock(t *testing.T) {
+	clock := payostest.NewFakeClock(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	serverErr := apierror.GenerateError(http.StatusServiceUnavailable, "", "unavailable", nil)
+	date := clock.Now().Add(30 * time.Second).Format(http.TimeFormat)
+
+	attempt := &RetryAttempt{Err: serverErr, Headers: http.Header{"Retry-After": {date}}}
+	backoff, ok := DefaultRetryPolicy{Clock: clock}.Retry(context.Background(), attempt)
+	if !ok || backoff != 30*time.Second {
+		t.Errorf("Retry() = %v, %v, want 30s", backoff, ok)
+	}
+}
+
+func TestCircuitBreakerClock(t *testing.T) {
+	clock := payostest.NewFakeClock(time.Now())
+	breaker := NewCircuitBreaker(CircuitBreakerOptions{FailureThreshold: 1, OpenTimeout: time.Minute, Clock: clock})
+
+	breaker.record(context.Background(), EndpointGroupPayouts, &http.Response{StatusCode: http.StatusBadGateway}, nil)
+	if got := breaker.State(EndpointGroupPayouts); got != CircuitOpen {
+		t.Fatalf("State() = %v, want open", got)
+	}
+	clock.Advance(time.Minute)
+	if got := breaker.State(EndpointGroupPayouts); got != CircuitHalfOpen {
+		t.Errorf("State() after OpenTimeout = %v, want half-open", got)
+	}
+}
@@ -0,0 +1,314 @@
+// Command payos-webhook signs payOS payment webhooks and delivers them to a local endpoint.
+//
+// It builds a webhook from flags or a JSON template, signs its data with the
+// checksum key exactly like payOS and POSTs it to the given URL:
+//
+//	payos-webhook -url http://localhost:8080/webhook -order-code 123 -amount 2000
+//
+// Captured payloads can be replayed from a directory of JSON files, optionally
+// signed again with the local checksum key:
+//
+//	payos-webhook -url http://localhost:8080/webhook -replay ./captured -resign
+//
+// The checksum key defaults to the PAYOS_CHECKSUM_KEY environment variable.
+// The command exits with a non-zero status when a webhook is not answered with 2xx.
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/payOSHQ/payos-lib-golang/v2/signature"
+)
+
+// config holds the parsed command line flags
+type config struct {
+	url         string
+	checksumKey string
+	template    string
+	replay      string
+	resign      bool
+	print       bool
+	timeout     time.Duration
+
+	// fields overrides the webhook data fields of the flags set on the command line
+	fields map[string]interface{}
+}
+
+// dataFlags maps the data field flags to their webhook data field and whether they are numeric
+var dataFlags = []struct {
+	flag    string
+	field   string
+	numeric bool
+	usage   string
+}{
+	{"order-code", "orderCode", true, "order code"},
+	{"amount", "amount", true, "paid amount"},
+	{"description", "description", false, "transfer description"},
+	{"account-number", "accountNumber", false, "receiving account number"},
+	{"reference", "reference", false, "bank transaction reference"},
+	{"transaction-date-time", "transactionDateTime", false, "transaction time, formatted 2006-01-02 15:04:05"},
+	{"currency", "currency", false, "currency"},
+	{"payment-link-id", "paymentLinkId", false, "payment link ID"},
+	{"code", "code", false, "payment result code, 00 for success"},
+	{"desc", "desc", false, "payment result description"},
+	{"counter-account-bank-id", "counterAccountBankId", false, "payer bank ID"},
+	{"counter-account-bank-name", "counterAccountBankName", false, "payer bank name"},
+	{"counter-account-name", "counterAccountName", false, "payer account name"},
+	{"counter-account-number", "counterAccountNumber", false, "payer account number"},
+	{"virtual-account-name", "virtualAccountName", false, "virtual account name"},
+	{"virtual-account-number", "virtualAccountNumber", false, "virtual account number"},
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "payos-webhook:", err)
+		os.Exit(1)
+	}
+}
+
+// run executes the command with the given arguments
+func run(args []string, stdout io.Writer) error {
+	cfg, err := parseFlags(args)
+	if err != nil {
+		return err
+	}
+
+	if cfg.replay != "" {
+		return replay(cfg, stdout)
+	}
+
+	payload, err := buildPayload(cfg)
+	if err != nil {
+		return err
+	}
+	if cfg.print {
+		_, err := fmt.Fprintln(stdout, string(payload))
+		return err
+	}
+	return deliver(cfg, "", payload, stdout)
+}
+
+// parseFlags parses the command line into a config
+func parseFlags(args []string) (*config, error) {
+	fs := flag.NewFlagSet("payos-webhook", flag.ContinueOnError)
+	cfg := &config{fields: make(map[string]interface{})}
+
+	fs.StringVar(&cfg.url, "url", "", "webhook endpoint to POST to")
+	fs.StringVar(&cfg.checksumKey, "checksum-key", os.Getenv("PAYOS_CHECKSUM_KEY"), "checksum key used to sign the data, defaults to PAYOS_CHECKSUM_KEY")
+	fs.StringVar(&cfg.template, "template", "", "JSON file with a webhook or its data object to start from")
+	fs.StringVar(&cfg.replay, "replay", "", "directory of captured webhook JSON files to deliver in name order")
+	fs.BoolVar(&cfg.resign, "resign", false, "sign replayed payloads again with the checksum key")
+	fs.BoolVar(&cfg.print, "print", false, "print the signed payload instead of delivering it")
+	fs.DurationVar(&cfg.timeout, "timeout", 10*time.Second, "delivery timeout")
+
+	values := make(map[string]*string, len(dataFlags))
+	for _, f := range dataFlags {
+		values[f.flag] = fs.String(f.flag, "", f.usage)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+
+	// Only the flags set on the command line override the template
+	var parseErr error
+	fs.Visit(func(fl *flag.Flag) {
+		for _, f := range dataFlags {
+			if f.flag != fl.Name {
+				continue
+			}
+			value := *values[f.flag]
+			if f.numeric {
+				number := json.Number(value)
+				if _, err := number.Int64(); err != nil {
+					parseErr = fmt.Errorf("-%s must be an integer: %q", f.flag, value)
+				}
+				cfg.fields[f.field] = number
+			} else {
+				cfg.fields[f.field] = value
+			}
+		}
+	})
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	if cfg.url == "" && !cfg.print {
+		return nil, errors.New("-url is required unless -print is set")
+	}
+	if cfg.checksumKey == "" && (cfg.replay == "" || cfg.resign) {
+		return nil, errors.New("-checksum-key or PAYOS_CHECKSUM_KEY is required")
+	}
+	return cfg, nil
+}
+
+// buildPayload builds and signs the webhook described by cfg
+func buildPayload(cfg *config) ([]byte, error) {
+	webhook := map[string]interface{}{
+		"code":    "00",
+		"desc":    "success",
+		"success": true,
+		"data":    defaultData(),
+	}
+
+	if cfg.template != "" {
+		template, err := readJSON(cfg.template)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := template["data"]; ok {
+			webhook = template
+		} else {
+			webhook["data"] = template
+		}
+	}
+
+	data, ok := webhook["data"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("webhook data must be a JSON object")
+	}
+	for field, value := range cfg.fields {
+		data[field] = value
+	}
+
+	return signWebhook(webhook, cfg.checksumKey)
+}
+
+// defaultData returns the data of a successful payment
+func defaultData() map[string]interface{} {
+	return map[string]interface{}{
+		"orderCode":              json.Number("123"),
+		"amount":                 json.Number("2000"),
+		"description":            "VQRIO123",
+		"accountNumber":          "12345678",
+		"reference":              "FT" + strings.ToUpper(randomHex(6)),
+		"transactionDateTime":    time.Now().Format("2006-01-02 15:04:05"),
+		"currency":               "VND",
+		"paymentLinkId":          randomHex(16),
+		"code":                   "00",
+		"desc":                   "success",
+		"counterAccountBankId":   "",
+		"counterAccountBankName": "",
+		"counterAccountName":     "",
+		"counterAccountNumber":   "",
+		"virtualAccountName":     "",
+		"virtualAccountNumber":   "",
+	}
+}
+
+// signWebhook sets the signature of the webhook data and encodes the webhook
+func signWebhook(webhook map[string]interface{}, checksumKey string) ([]byte, error) {
+	sig, err := signature.Sign(signature.SchemeBody, checksumKey, webhook["data"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign webhook data: %w", err)
+	}
+	webhook["signature"] = sig
+	return json.Marshal(webhook)
+}
+
+// replay delivers every JSON file of the replay directory in name order
+func replay(cfg *config, stdout io.Writer) error {
+	files, err := filepath.Glob(filepath.Join(cfg.replay, "*.json"))
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no JSON files in %s", cfg.replay)
+	}
+	sort.Strings(files)
+
+	// Every file is delivered, then the rejected ones are reported
+	var rejected []string
+	for _, file := range files {
+		payload, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		if cfg.resign {
+			webhook, err := readJSON(file)
+			if err != nil {
+				return err
+			}
+			if payload, err = signWebhook(webhook, cfg.checksumKey); err != nil {
+				return fmt.Errorf("%s: %w", file, err)
+			}
+		}
+
+		if cfg.print {
+			fmt.Fprintln(stdout, string(payload))
+			continue
+		}
+		if err := deliver(cfg, filepath.Base(file), payload, stdout); err != nil {
+			if !errors.Is(err, errRejected) {
+				return err
+			}
+			rejected = append(rejected, filepath.Base(file))
+		}
+	}
+	if len(rejected) > 0 {
+		return fmt.Errorf("%w: %s", errRejected, strings.Join(rejected, ", "))
+	}
+	return nil
+}
+
+// errRejected reports a webhook answered with a status outside 2xx
+var errRejected = errors.New("webhook rejected")
+
+// deliver POSTs the payload to the webhook URL and reports the answer
+func deliver(cfg *config, name string, payload []byte, stdout io.Writer) error {
+	client := &http.Client{Timeout: cfg.timeout}
+	resp, err := client.Post(cfg.url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to deliver webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+	if name != "" {
+		fmt.Fprintf(stdout, "%s: ", name)
+	}
+	fmt.Fprintf(stdout, "%s %s\n", resp.Status, strings.TrimSpace(string(body)))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%w with %s", errRejected, resp.Status)
+	}
+	return nil
+}
+
+// readJSON decodes a JSON object file, keeping numbers exact
+func readJSON(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value map[string]interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("%s: invalid JSON object: %w", path, err)
+	}
+	return value, nil
+}
+
+// randomHex returns n random bytes encoded as hex
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
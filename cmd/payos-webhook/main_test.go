@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/payOSHQ/payos-lib-golang/v2"
+)
+
+const testChecksumKey = "test-checksum-key"
+
+// newTestWebhooks returns a client able to verify webhooks signed with testChecksumKey
+func newTestWebhooks(t *testing.T) *payos.Webhooks {
+	t.Helper()
+	client, err := payos.NewPayOS(&payos.PayOSOptions{
+		ClientId:    "client-id",
+		ApiKey:      "api-key",
+		ChecksumKey: testChecksumKey,
+	})
+	if err != nil {
+		t.Fatalf("NewPayOS() error = %v", err)
+	}
+	return client.Webhooks
+}
+
+func TestPrintPayload(t *testing.T) {
+	var out bytes.Buffer
+	err := run([]string{"-print", "-checksum-key", testChecksumKey, "-order-code", "9007199254740991", "-amount", "1000000", "-counter-account-name", "NGUYEN VAN A"}, &out)
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+
+	webhook, err := newTestWebhooks(t).Verify(context.Background(), out.Bytes())
+	if err != nil {
+		t.Fatalf("Verify() error = %v\n%s", err, out.String())
+	}
+	if webhook.Data.OrderCode != 9007199254740991 || webhook.Data.Amount != 1000000 ||
+		webhook.Data.CounterAccountName == nil || *webhook.Data.CounterAccountName != "NGUYEN VAN A" {
+		t.Errorf("webhook data = %+v", webhook.Data)
+	}
+}
+
+func TestTemplateAndDelivery(t *testing.T) {
+	dir := t.TempDir()
+	template := filepath.Join(dir, "template.json")
+	os.WriteFile(template, []byte(`{"orderCode": 42, "amount": 5000, "description": "template", "reference": "REF1", "paymentLinkId": "link", "code": "00", "desc": "success"}`), 0o600)
+
+	var received []*payos.WebhookData
+	receiver := httptest.NewServer(newTestWebhooks(t).Handler(&payos.WebhookHandlerOptions{
+		OnPaymentSucceeded: func(ctx context.Context, data *payos.WebhookData) error {
+			received = append(received, data)
+			return nil
+		},
+	}))
+	defer receiver.Close()
+
+	var out bytes.Buffer
+	err := run([]string{"-url", receiver.URL, "-checksum-key", testChecksumKey, "-template", template, "-amount", "7000"}, &out)
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "200 OK") {
+		t.Errorf("output = %s", out.String())
+	}
+	if len(received) != 1 || received[0].OrderCode != 42 || received[0].Amount != 7000 || received[0].Description != "template" {
+		t.Errorf("received = %+v", received)
+	}
+
+	// A webhook signed with another key is answered with 401 and fails the command
+	out.Reset()
+	err = run([]string{"-url", receiver.URL, "-checksum-key", "other-key", "-template", template}, &out)
+	if !errors.Is(err, errRejected) || !strings.Contains(err.Error(), "401") {
+		t.Errorf("run() of a rejected webhook error = %v, want errRejected with 401", err)
+	}
+	if !strings.HasPrefix(out.String(), "401") {
+		t.Errorf("output = %s", out.String())
+	}
+}
+
+func TestReplay(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "01.json"), []byte(`{"code":"00","desc":"success","data":{"orderCode":1,"amount":100,"reference":"A","paymentLinkId":"l1","code":"00"},"signature":"stale"}`), 0o600)
+	os.WriteFile(filepath.Join(dir, "02.json"), []byte(`{"code":"00","desc":"success","data":{"orderCode":2,"amount":200,"reference":"B","paymentLinkId":"l2","code":"00"},"signature":"stale"}`), 0o600)
+
+	var orders []int64
+	receiver := httptest.NewServer(newTestWebhooks(t).Handler(&payos.WebhookHandlerOptions{
+		OnPaymentSucceeded: func(ctx context.Context, data *payos.WebhookData) error {
+			orders = append(orders, data.OrderCode)
+			return nil
+		},
+	}))
+	defer receiver.Close()
+
+	var out bytes.Buffer
+	if err := run([]string{"-url", receiver.URL, "-replay", dir}, &out); !errors.Is(err, errRejected) || !strings.Contains(err.Error(), "01.json, 02.json") {
+		t.Errorf("run() error = %v, want both files rejected", err)
+	}
+	if len(orders) != 0 || strings.Count(out.String(), "401") != 2 {
+		t.Errorf("replay without -resign: orders = %v, output = %s", orders, out.String())
+	}
+
+	out.Reset()
+	if err := run([]string{"-url", receiver.URL, "-replay", dir, "-resign", "-checksum-key", testChecksumKey}, &out); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if len(orders) != 2 || orders[0] != 1 || orders[1] != 2 {
+		t.Errorf("replay with -resign: orders = %v, output = %s", orders, out.String())
+	}
+}
+
+func TestFlagValidation(t *testing.T) {
+	t.Setenv("PAYOS_CHECKSUM_KEY", "")
+	for _, args := range [][]string{
+		{"-print"},
+		{"-checksum-key", testChecksumKey},
+		{"-print", "-checksum-key", testChecksumKey, "-amount", "ten"},
+	} {
+		if err := run(args, &bytes.Buffer{}); err == nil {
+			t.Errorf("run(%q) error = nil", args)
+		}
+	}
+}
@@ -0,0 +1,366 @@
+package payos
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/payOSHQ/payos-lib-golang/v2/apierror"
+	"github.com/payOSHQ/payos-lib-golang/v2/signature"
+)
+
+// Defaults of CredentialsCacheOptions
+const (
+	DefaultCredentialsRefreshInterval = 30 * time.Second
+	DefaultChecksumRotationWindow     = 24 * time.Hour
+)
+
+// Credentials are the keys of a payOS channel
+type Credentials struct {
+	ClientId    string `json:"clientId"`
+	ApiKey      string `json:"apiKey"`
+	ChecksumKey string `json:"checksumKey"`
+
+	// PreviousChecksumKeys are still accepted when verifying response and webhook signatures,
+	// so webhooks signed before a key rotation keep verifying during the rotation window
+	// Requests are always signed with ChecksumKey
+	PreviousChecksumKeys []string `json:"previousChecksumKeys,omitempty"`
+}
+
+// validate reports the first missing key
+func (c Credentials) validate() error {
+	switch {
+	case c.ClientId == "":
+		return apierror.NewCredentialsError("client ID is missing or empty", nil)
+	case c.ApiKey == "":
+		return apierror.NewCredentialsError("API key is missing or empty", nil)
+	case c.ChecksumKey == "":
+		return apierror.NewCredentialsError("checksum key is missing or empty", nil)
+	}
+	return nil
+}
+
+// verify checks sig against the checksum key, then against the previous checksum keys
+// The error of the current checksum key is returned when none of them match
+func (c Credentials) verify(scheme signature.Scheme, data interface{}, sig string) error {
+	err := signature.Verify(scheme, c.ChecksumKey, data, sig)
+	if err == nil {
+		return nil
+	}
+	for _, key := range c.PreviousChecksumKeys {
+		if signature.Verify(scheme, key, data, sig) == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// CredentialsProvider supplies the credentials of a client, see PayOSOptions.CredentialsProvider
+// It is consulted before every request and webhook verification, so rotated keys are used without a restart
+// Implementations must be safe for concurrent use and cache credentials that are slow to load
+type CredentialsProvider interface {
+	Credentials(ctx context.Context) (Credentials, error)
+}
+
+// CredentialsProviderFunc adapts a function to a CredentialsProvider
+type CredentialsProviderFunc func(ctx context.Context) (Credentials, error)
+
+// Credentials implements CredentialsProvider
+func (f CredentialsProviderFunc) Credentials(ctx context.Context) (Credentials, error) {
+	return f(ctx)
+}
+
+// StaticCredentials returns a provider of fixed credentials
+func StaticCredentials(creds Credentials) CredentialsProvider {
+	return staticCredentials(creds)
+}
+
+type staticCredentials Credentials
+
+func (s staticCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	return Credentials(s), nil
+}
+
+// NewEnvCredentials returns a provider reading the environment on every call
+// With the PAYOS prefix it reads PAYOS_CLIENT_ID, PAYOS_API_KEY, PAYOS_CHECKSUM_KEY
+// and the comma separated PAYOS_PREVIOUS_CHECKSUM_KEYS
+// Prefix defaults to PAYOS
+func NewEnvCredentials(prefix string) CredentialsProvider {
+	if prefix == "" {
+		prefix = "PAYOS"
+	}
+	return CredentialsProviderFunc(func(ctx context.Context) (Credentials, error) {
+		creds := Credentials{
+			ClientId:    os.Getenv(prefix + "_CLIENT_ID"),
+			ApiKey:      os.Getenv(prefix + "_API_KEY"),
+			ChecksumKey: os.Getenv(prefix + "_CHECKSUM_KEY"),
+		}
+		for _, key := range strings.Split(os.Getenv(prefix+"_PREVIOUS_CHECKSUM_KEYS"), ",") {
+			if key = strings.TrimSpace(key); key != "" {
+				creds.PreviousChecksumKeys = append(creds.PreviousChecksumKeys, key)
+			}
+		}
+		for _, v := range []struct{ name, value string }{
+			{"_CLIENT_ID", creds.ClientId},
+			{"_API_KEY", creds.ApiKey},
+			{"_CHECKSUM_KEY", creds.ChecksumKey},
+		} {
+			if v.value == "" {
+				return Credentials{}, apierror.NewCredentialsError(fmt.Sprintf("the %s environment variable is missing or empty", prefix+v.name), nil)
+			}
+		}
+		return creds, nil
+	})
+}
+
+// NewPollingFileCredentials returns a provider reading the credentials from a JSON file:
+//
+//	{"clientId": "...", "apiKey": "...", "checksumKey": "...", "previousChecksumKeys": ["..."]}
+//
+// The file is not watched: it is polled, read again by the first call after each RefreshInterval,
+// so a rotated key is picked up within RefreshInterval without a restart; call Refresh to pick it up at once
+// It fails if the file cannot be read now
+func NewPollingFileCredentials(path string, opts *CredentialsCacheOptions) (*CachedCredentials, error) {
+	provider := NewCachedCredentials(CredentialsProviderFunc(func(ctx context.Context) (Credentials, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return Credentials{}, apierror.NewCredentialsError("failed to read credentials file", err)
+		}
+		var creds Credentials
+		if err := json.Unmarshal(data, &creds); err != nil {
+			return Credentials{}, apierror.NewCredentialsError(fmt.Sprintf("invalid credentials file %s", path), err)
+		}
+		if err := creds.validate(); err != nil {
+			return Credentials{}, fmt.Errorf("%s: %w", path, err)
+		}
+		return creds, nil
+	}), opts)
+
+	if _, err := provider.Credentials(context.Background()); err != nil {
+		return nil, err
+	}
+	return provider, nil
+}
+
+// CredentialsCacheOptions configures a CachedCredentials
+type CredentialsCacheOptions struct {
+	// RefreshInterval is how long credentials are cached before they are loaded again
+	// Defaults to DefaultCredentialsRefreshInterval
+	RefreshInterval time.Duration
+
+	// RotationWindow is how long a replaced checksum key keeps verifying signatures
+	// Defaults to DefaultChecksumRotationWindow
+	RotationWindow time.Duration
+
+	// OnRefreshError is called when loading fails while cached credentials are still served
+	OnRefreshError func(err error)
+
+	// Clock times the cache and the rotation window
+	// Defaults to SystemClock
+	Clock Clock
+}
+
+// CachedCredentials caches the credentials of another provider
+// Credentials are loaded outside the cache lock, by one caller at a time: while a refresh is in
+// flight the other callers are served the cached credentials, or wait for it before the first load
+// When loading fails after a successful load, the cached credentials are served until the next refresh
+// When the checksum key changes, the replaced key is added to PreviousChecksumKeys for the rotation window
+type CachedCredentials struct {
+	next CredentialsProvider
+	opts CredentialsCacheOptions
+
+	mu       sync.Mutex
+	creds    Credentials
+	loaded   bool
+	loadedAt time.Time
+	retired  map[string]time.Time
+	loading  chan struct{} // closed when the load in flight finishes
+	loadErr  error         // error of the last finished load
+}
+
+// NewCachedCredentials returns a provider caching the credentials of next
+func NewCachedCredentials(next CredentialsProvider, opts *CredentialsCacheOptions) *CachedCredentials {
+	var o CredentialsCacheOptions
+	if opts != nil {
+		o = *opts
+	}
+	o.RefreshInterval = getDurationValue(o.RefreshInterval, DefaultCredentialsRefreshInterval)
+	o.RotationWindow = getDurationValue(o.RotationWindow, DefaultChecksumRotationWindow)
+	o.Clock = orSystemClock(o.Clock)
+	return &CachedCredentials{next: next, opts: o, retired: make(map[string]time.Time)}
+}
+
+// Credentials implements CredentialsProvider
+// A caller waiting for the first load returns early with the error of ctx when it is done
+func (c *CachedCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.opts.Clock.Now()
+	fresh := c.loaded && now.Sub(c.loadedAt) < c.opts.RefreshInterval
+	refreshing := c.loaded && c.loading != nil
+	if !fresh && !refreshing {
+		c.mu.Unlock()
+		err := c.load(ctx)
+		c.mu.Lock()
+		if err != nil {
+			if !c.loaded {
+				return Credentials{}, err
+			}
+			if c.opts.OnRefreshError != nil {
+				c.opts.OnRefreshError(err)
+			}
+		}
+		now = c.opts.Clock.Now()
+	}
+	return c.withRetired(now), nil
+}
+
+// Refresh loads the credentials now, for example on SIGHUP
+// It joins a load already in flight, and the cached credentials are kept when loading fails
+func (c *CachedCredentials) Refresh(ctx context.Context) error {
+	return c.load(ctx)
+}
+
+// load replaces the cached credentials with those of the next provider
+// Only one load runs at a time; a load started by another caller is waited for until ctx is done
+func (c *CachedCredentials) load(ctx context.Context) error {
+	c.mu.Lock()
+	if done := c.loading; done != nil {
+		c.mu.Unlock()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.loadErr
+	}
+	done := make(chan struct{})
+	c.loading = done
+	c.mu.Unlock()
+
+	creds, err := c.next.Credentials(ctx)
+	if err == nil {
+		err = creds.validate()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.opts.Clock.Now()
+	c.loading, c.loadErr = nil, err
+	close(done)
+	if err != nil {
+		if c.loaded {
+			// Serve the cached credentials and try again after the next interval
+			c.loadedAt = now
+		}
+		return err
+	}
+
+	if c.loaded && c.creds.ChecksumKey != creds.ChecksumKey {
+		c.retired[c.creds.ChecksumKey] = now.Add(c.opts.RotationWindow)
+	}
+	delete(c.retired, creds.ChecksumKey)
+	c.creds = creds
+	c.loaded = true
+	c.loadedAt = now
+	return nil
+}
+
+// withRetired returns the cached credentials with the checksum keys replaced during the rotation window
+func (c *CachedCredentials) withRetired(now time.Time) Credentials {
+	creds := c.creds
+	creds.PreviousChecksumKeys = append([]string(nil), c.creds.PreviousChecksumKeys...)
+	for key, expires := range c.retired {
+		if !now.Before(expires) {
+			delete(c.retired, key)
+			continue
+		}
+		creds.PreviousChecksumKeys = append(creds.PreviousChecksumKeys, key)
+	}
+	return creds
+}
+
+// credentials returns the provider of the channel, failing on the first missing key
+// prefix and option name the environment variable and option of the error
+func (o *ChannelOptions) credentials(prefix, option string) (CredentialsProvider, error) {
+	if o.CredentialsProvider != nil {
+		return o.CredentialsProvider, nil
+	}
+	for _, v := range []struct{ env, field, value string }{
+		{"_CLIENT_ID", "ClientId", o.ClientId},
+		{"_API_KEY", "ApiKey", o.ApiKey},
+		{"_CHECKSUM_KEY", "ChecksumKey", o.ChecksumKey},
+	} {
+		if v.value == "" {
+			return nil, apierror.NewPayOSError(fmt.Sprintf("The %s%s environment variable is missing or empty; either provide it, or instantiate the PayOS client with a %s%s option.", prefix, v.env, option, v.field))
+		}
+	}
+	return StaticCredentials(Credentials{
+		ClientId:    o.ClientId,
+		ApiKey:      o.ApiKey,
+		ChecksumKey: o.ChecksumKey,
+	}), nil
+}
+
+// missingCredentials fails every call of a channel configured without credentials
+func missingCredentials(channel, option, prefix string) CredentialsProvider {
+	err := apierror.NewCredentialsError(fmt.Sprintf("no %s channel credentials; set PayOSOptions.%s or the %s_CLIENT_ID, %s_API_KEY and %s_CHECKSUM_KEY environment variables", channel, option, prefix, prefix, prefix), nil)
+	return CredentialsProviderFunc(func(ctx context.Context) (Credentials, error) {
+		return Credentials{}, err
+	})
+}
+
+// channelCredentials returns the providers of the payment and payout channels of resolved options
+// Without Payment and Payout, both channels share the required top level credentials
+// Otherwise a channel without credentials fails its calls with an error matching apierror.ErrCredentials
+func (o *PayOSOptions) channelCredentials() (payment, payout CredentialsProvider, err error) {
+	top := &ChannelOptions{
+		ClientId:            o.ClientId,
+		ApiKey:              o.ApiKey,
+		ChecksumKey:         o.ChecksumKey,
+		CredentialsProvider: o.CredentialsProvider,
+	}
+	if o.Payment == nil && o.Payout == nil {
+		payment, err = top.credentials("PAYOS", "")
+		return payment, payment, err
+	}
+
+	paymentOpts, option := top, ""
+	if o.Payment != nil {
+		paymentOpts, option = o.Payment, "Payment."
+	}
+	payment = missingCredentials("payment", "Payment", "PAYOS")
+	if paymentOpts.configured() {
+		if payment, err = paymentOpts.credentials("PAYOS", option); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	payout = missingCredentials("payout", "Payout", "PAYOS_PAYOUT")
+	if o.Payout != nil && o.Payout.configured() {
+		if payout, err = o.Payout.credentials("PAYOS_PAYOUT", "Payout."); err != nil {
+			return nil, nil, err
+		}
+	}
+	return payment, payout, nil
+}
+
+// loadCredentials returns the credentials of the next request or webhook verification
+func (c *Client) loadCredentials(ctx context.Context) (Credentials, error) {
+	creds, err := c.credentials.Credentials(ctx)
+	if err == nil {
+		err = creds.validate()
+	}
+	if err != nil && !errors.Is(err, apierror.ErrCredentials) {
+		err = apierror.NewCredentialsError("failed to load credentials", err)
+	}
+	return creds, err
+}
@@ -0,0 +1,261 @@
+package payos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/payOSHQ/payos-lib-golang/v2/apierror"
+	"github.com/payOSHQ/payos-lib-golang/v2/payostest"
+)
+
+func TestEnvCredentials(t *testing.T) {
+	t.Setenv("PAYOS_TEST_CLIENT_ID", "client-id")
+	t.Setenv("PAYOS_TEST_API_KEY", "api-key")
+	t.Setenv("PAYOS_TEST_CHECKSUM_KEY", "new-key")
+	t.Setenv("PAYOS_TEST_PREVIOUS_CHECKSUM_KEYS", "old-key, older-key")
+	provider := NewEnvCredentials("PAYOS_TEST")
+
+	creds, err := provider.Credentials(context.Background())
+	if err != nil {
+		t.Fatalf("Credentials() error = %v", err)
+	}
+	if creds.ClientId != "client-id" || creds.ChecksumKey != "new-key" || !slices.Equal(creds.PreviousChecksumKeys, []string{"old-key", "older-key"}) {
+		t.Errorf("Credentials() = %+v", creds)
+	}
+
+	t.Setenv("PAYOS_TEST_API_KEY", "")
+	_, err = provider.Credentials(context.Background())
+	if !errors.Is(err, apierror.ErrCredentials) || !strings.Contains(err.Error(), "PAYOS_TEST_API_KEY") {
+		t.Errorf("Credentials() error = %v, want the missing PAYOS_TEST_API_KEY", err)
+	}
+}
+
+func TestCachedCredentialsRotation(t *testing.T) {
+	clock := payostest.NewFakeClock(time.Now())
+	var mu sync.Mutex
+	current := Credentials{ClientId: "id", ApiKey: "key", ChecksumKey: "key-1"}
+	var loadErr error
+	var refreshErrors int
+
+	provider := NewCachedCredentials(CredentialsProviderFunc(func(ctx context.Context) (Credentials, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return current, loadErr
+	}), &CredentialsCacheOptions{
+		RefreshInterval: time.Minute,
+		RotationWindow:  time.Hour,
+		OnRefreshError:  func(err error) { refreshErrors++ },
+		Clock:           clock,
+	})
+	ctx := context.Background()
+	checksumKey := func() (string, []string) {
+		t.Helper()
+		creds, err := provider.Credentials(ctx)
+		if err != nil {
+			t.Fatalf("Credentials() error = %v", err)
+		}
+		return creds.ChecksumKey, creds.PreviousChecksumKeys
+	}
+
+	checksumKey()
+	mu.Lock()
+	current.ChecksumKey = "key-2"
+	mu.Unlock()
+	if key, _ := checksumKey(); key != "key-1" {
+		t.Errorf("ChecksumKey = %s before RefreshInterval, want the cached key-1", key)
+	}
+
+	clock.Advance(time.Minute)
+	if key, previous := checksumKey(); key != "key-2" || !slices.Equal(previous, []string{"key-1"}) {
+		t.Errorf("after rotation = %s %v, want key-2 with previous key-1", key, previous)
+	}
+
+	mu.Lock()
+	loadErr = errors.New("secret store unavailable")
+	mu.Unlock()
+	clock.Advance(time.Minute)
+	if key, _ := checksumKey(); key != "key-2" || refreshErrors != 1 {
+		t.Errorf("after a failed refresh = %s with %d refresh errors, want the cached key-2 and 1 error", key, refreshErrors)
+	}
+
+	clock.Advance(time.Hour)
+	if _, previous := checksumKey(); len(previous) != 0 {
+		t.Errorf("PreviousChecksumKeys = %v after the rotation window", previous)
+	}
+}
+
+func TestCachedCredentialsConcurrentLoad(t *testing.T) {
+	clock := payostest.NewFakeClock(time.Now())
+	var loads atomic.Int32
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	provider := NewCachedCredentials(CredentialsProviderFunc(func(ctx context.Context) (Credentials, error) {
+		n := loads.Add(1)
+		started <- struct{}{}
+		<-release
+		return Credentials{ClientId: "id", ApiKey: "key", ChecksumKey: fmt.Sprintf("key-%d", n)}, nil
+	}), &CredentialsCacheOptions{RefreshInterval: time.Minute, Clock: clock})
+	ctx := context.Background()
+
+	// Callers waiting for the first load share it, and their context cuts the wait short
+	first := make(chan error, 1)
+	go func() {
+		_, err := provider.Credentials(ctx)
+		first <- err
+	}()
+	<-started
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := provider.Credentials(canceled); !errors.Is(err, context.Canceled) {
+		t.Errorf("Credentials() with a cancelled context during the first load error = %v", err)
+	}
+	release <- struct{}{}
+	if err := <-first; err != nil {
+		t.Fatalf("Credentials() error = %v", err)
+	}
+
+	// While a refresh is in flight, other callers are served the cached credentials
+	clock.Advance(time.Minute)
+	refreshed := make(chan Credentials, 1)
+	go func() {
+		creds, _ := provider.Credentials(ctx)
+		refreshed <- creds
+	}()
+	<-started
+	if creds, err := provider.Credentials(ctx); err != nil || creds.ChecksumKey != "key-1" {
+		t.Errorf("Credentials() during a refresh = %s, %v, want the cached key-1", creds.ChecksumKey, err)
+	}
+	release <- struct{}{}
+	if creds := <-refreshed; creds.ChecksumKey != "key-2" {
+		t.Errorf("refreshed ChecksumKey = %s, want key-2", creds.ChecksumKey)
+	}
+	if got := loads.Load(); got != 2 {
+		t.Errorf("loads = %d, want 2", got)
+	}
+}
+
+func TestFileCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "payos.json")
+	write := func(checksumKey string) {
+		data := `{"clientId": "id", "apiKey": "key", "checksumKey": "` + checksumKey + `"}`
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := NewPollingFileCredentials(path, nil); !errors.Is(err, apierror.ErrCredentials) {
+		t.Errorf("NewPollingFileCredentials() of a missing file error = %v, want ErrCredentials", err)
+	}
+
+	write("key-1")
+	clock := payostest.NewFakeClock(time.Now())
+	provider, err := NewPollingFileCredentials(path, &CredentialsCacheOptions{Clock: clock})
+	if err != nil {
+		t.Fatalf("NewPollingFileCredentials() error = %v", err)
+	}
+
+	write("key-2")
+	clock.Advance(DefaultCredentialsRefreshInterval)
+	creds, err := provider.Credentials(context.Background())
+	if err != nil {
+		t.Fatalf("Credentials() error = %v", err)
+	}
+	if creds.ChecksumKey != "key-2" || !slices.Equal(creds.PreviousChecksumKeys, []string{"key-1"}) {
+		t.Errorf("Credentials() = %+v, want key-2 with previous key-1", creds)
+	}
+}
+
+func TestClientCredentialsProvider(t *testing.T) {
+	srv := payostest.NewServer(&payostest.Options{PayoutBalance: 1000})
+	defer srv.Close()
+
+	var mu sync.Mutex
+	apiKey := "revoked-key"
+	client, err := NewPayOS(&PayOSOptions{
+		BaseURL: srv.URL,
+		CredentialsProvider: CredentialsProviderFunc(func(ctx context.Context) (Credentials, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return Credentials{ClientId: srv.ClientId, ApiKey: apiKey, ChecksumKey: srv.ChecksumKey}, nil
+		}),
+	})
+	if err != nil {
+		t.Fatalf("NewPayOS() error = %v", err)
+	}
+
+	ctx := context.Background()
+	if _, err := client.PayoutsAccount.Balance(ctx); !errors.Is(err, apierror.ErrUnauthorized) {
+		t.Errorf("Balance() with a revoked key error = %v, want ErrUnauthorized", err)
+	}
+	mu.Lock()
+	apiKey = srv.ApiKey
+	mu.Unlock()
+	if _, err := client.PayoutsAccount.Balance(ctx); err != nil {
+		t.Errorf("Balance() after rotation error = %v", err)
+	}
+}
+
+func TestClientCredentialsError(t *testing.T) {
+	var calls int
+	client, err := NewPayOS(&PayOSOptions{
+		BaseURL: "http://127.0.0.1:0",
+		CredentialsProvider: CredentialsProviderFunc(func(ctx context.Context) (Credentials, error) {
+			calls++
+			return Credentials{}, errors.New("vault sealed")
+		}),
+	})
+	if err != nil {
+		t.Fatalf("NewPayOS() error = %v", err)
+	}
+
+	_, err = client.PayoutsAccount.Balance(context.Background())
+	if !errors.Is(err, apierror.ErrCredentials) || !strings.Contains(err.Error(), "vault sealed") {
+		t.Errorf("Balance() error = %v, want ErrCredentials", err)
+	}
+	if calls != 1 {
+		t.Errorf("provider called %d times, want 1 without retries", calls)
+	}
+}
+
+func TestWebhookVerifyDuringRotation(t *testing.T) {
+	client, srv := newTestPayOS(t)
+	if _, err := client.PaymentRequests.Create(context.Background(), testPaymentLinkRequest(811)); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := srv.MarkPaid(811); err != nil {
+		t.Fatalf("MarkPaid() error = %v", err)
+	}
+	payload, err := srv.WebhookPayload(811)
+	if err != nil {
+		t.Fatalf("WebhookPayload() error = %v", err)
+	}
+
+	rotated := func(previous ...string) *PayOS {
+		client, err := NewPayOS(&PayOSOptions{CredentialsProvider: StaticCredentials(Credentials{
+			ClientId:             "id",
+			ApiKey:               "key",
+			ChecksumKey:          "new-checksum-key",
+			PreviousChecksumKeys: previous,
+		})})
+		if err != nil {
+			t.Fatalf("NewPayOS() error = %v", err)
+		}
+		return client
+	}
+
+	if _, err := rotated(srv.ChecksumKey).Webhooks.Verify(context.Background(), payload); err != nil {
+		t.Errorf("Verify() with the previous key error = %v", err)
+	}
+	if _, err := rotated().Webhooks.Verify(context.Background(), payload); !errors.Is(err, apierror.ErrInvalidSignature) {
+		t.Errorf("Verify() without the previous key error = %v, want ErrInvalidSignature", err)
+	}
+}
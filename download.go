@@ -0,0 +1,350 @@
+package payos
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/payOSHQ/payos-lib-golang/v2/apierror"
+)
+
+// FileInfo describes a downloaded file
+type FileInfo struct {
+	Filename    *string
+	ContentType string
+	Size        *int64
+}
+
+// FileStream is a file download read directly from the response body
+// An interrupted transfer is resumed with a Range request while retries remain
+// The caller must call Close when finished
+type FileStream struct {
+	FileInfo
+
+	client    *Client
+	ctx       context.Context
+	cancel    context.CancelFunc
+	cfg       *requestConfig
+	path      string
+	url       string
+	body      io.ReadCloser
+	read      int64
+	attempt   int
+	validator string
+	attempts  []apierror.Attempt
+	err       error
+	start     time.Time
+	span      Span
+	observed  bool
+}
+
+// DownloadStream opens a streaming download of the file at path
+// The request goes through the same middleware and retry pipeline as Request
+func (c *Client) DownloadStream(ctx context.Context, path string, reqOpts ...RequestOption) (*FileStream, error) {
+	cfg := c.newRequestConfig(reqOpts)
+	start := c.clock.Now()
+	defer func() { cfg.captureLatency(c.clock.Now().Sub(start)) }()
+
+	fullURL, err := c.buildURL(cfg.baseURL, path, nil)
+	if err != nil {
+		return nil, apierror.NewConnectionError("failed to build URL", err)
+	}
+
+	stream := &FileStream{
+		client: c,
+		ctx:    ctx,
+		cfg:    cfg,
+		path:   path,
+		url:    fullURL,
+		start:  start,
+	}
+	if cfg.timeout > 0 {
+		stream.ctx, stream.cancel = context.WithTimeout(ctx, cfg.timeout)
+	}
+	stream.ctx, stream.span = c.startOperationSpan(stream.ctx, cfg, "GET", path)
+
+	if err := stream.open(); err != nil {
+		stream.err = err
+		stream.Close()
+		return nil, err
+	}
+	return stream, nil
+}
+
+// Read reads the file, resuming the transfer if the connection drops
+func (s *FileStream) Read(p []byte) (int, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+
+	for {
+		n, err := s.body.Read(p)
+		s.read += int64(n)
+
+		if err == io.EOF && s.Size != nil && s.read != *s.Size {
+			if s.read > *s.Size {
+				s.err = apierror.NewConnectionError(fmt.Sprintf("received %d bytes, more than Content-Length %d", s.read, *s.Size), nil)
+				return n, s.err
+			}
+			err = io.ErrUnexpectedEOF
+		}
+		if err == nil || err == io.EOF {
+			return n, err
+		}
+
+		if resumeErr := s.resume(err); resumeErr != nil {
+			s.err = resumeErr
+			return n, s.err
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+// Close closes the response body and releases the stream resources
+// Reads after Close return os.ErrClosed
+func (s *FileStream) Close() error {
+	if !s.observed {
+		s.observed = true
+		s.client.observeRequest(s.ctx, s.cfg, "GET", s.path, s.attempt+1, s.start, s.err)
+		endOperationSpan(s.span, s.attempt+1, s.err)
+	}
+	if s.err == nil {
+		s.err = os.ErrClosed
+	}
+
+	var err error
+	if s.body != nil {
+		err = s.body.Close()
+		s.body = nil
+	}
+	if s.cancel != nil {
+		s.cancel()
+	}
+	return err
+}
+
+// resume reopens the transfer at the current offset after a read error
+func (s *FileStream) resume(cause error) error {
+	s.body.Close()
+	s.body = nil
+	s.attempts = append(s.attempts, apierror.Attempt{Err: cause})
+
+	if s.ctx.Err() != nil {
+		return s.operationError(apierror.NewConnectionTimeoutError("download cancelled or timed out"))
+	}
+	err := apierror.NewConnectionError("download interrupted", cause)
+	if s.attempt >= s.cfg.maxRetries {
+		return s.operationError(err)
+	}
+	backoff, shouldRetry := s.retryDelay(err)
+	if !shouldRetry {
+		return s.operationError(err)
+	}
+	s.attempts[len(s.attempts)-1].Backoff = backoff
+	retryEvent(s.span, s.attempt, backoff)
+	if err := sleepContext(s.ctx, s.client.clock, backoff); err != nil {
+		return s.operationError(err)
+	}
+	s.attempt++
+	return s.open()
+}
+
+// open requests the file from the current offset, retrying failed attempts
+func (s *FileStream) open() error {
+	for {
+		err := s.openAttempt()
+		if err == nil {
+			observeRetrySuccess(s.ctx, s.cfg.retryPolicy)
+			return nil
+		}
+		s.attempts = append(s.attempts, newAttempt(err))
+		if s.attempt >= s.cfg.maxRetries {
+			return s.operationError(err)
+		}
+		backoff, shouldRetry := s.retryDelay(err)
+		if !shouldRetry {
+			return s.operationError(err)
+		}
+		s.attempts[len(s.attempts)-1].Backoff = backoff
+		retryEvent(s.span, s.attempt, backoff)
+		if err := sleepContext(s.ctx, s.client.clock, backoff); err != nil {
+			return s.operationError(err)
+		}
+		s.attempt++
+	}
+}
+
+// retryDelay asks the retry policy whether a failed attempt of the download is retried
+func (s *FileStream) retryDelay(err error) (time.Duration, bool) {
+	return s.client.retryDelay(s.ctx, s.cfg, &RetryAttempt{
+		Method:  "GET",
+		Path:    s.path,
+		Attempt: s.attempt,
+		Err:     err,
+	})
+}
+
+// operationError wraps the final error of the download with its attempts
+func (s *FileStream) operationError(err error) error {
+	return s.cfg.operationError("GET", s.path, "", s.attempts, err)
+}
+
+// openAttempt sends a single download request and sets up the response body
+func (s *FileStream) openAttempt() (err error) {
+	ctx, span, timings := s.client.startAttemptSpan(withAttempt(s.ctx, s.attempt), "GET", s.path, s.attempt)
+
+	var statusCode int
+	var code string
+	defer func(start time.Time) {
+		s.client.observeAttempt(s.ctx, s.cfg, AttemptMetrics{
+			Method:     "GET",
+			Path:       s.path,
+			Attempt:    s.attempt,
+			StatusCode: statusCode,
+			Code:       code,
+			Err:        err,
+		}, start)
+		endAttemptSpan(span, timings, statusCode, code, err)
+	}(s.client.clock.Now())
+
+	creds, err := s.client.loadCredentials(ctx)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", s.url, nil)
+	if err != nil {
+		return apierror.NewConnectionError("failed to create request", err)
+	}
+	req.Header = s.client.buildHeaders(creds, nil)
+	s.cfg.applyHeaders(req.Header)
+	injectTraceParent(req.Header, span)
+	if s.read > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", s.read))
+		if s.validator != "" {
+			req.Header.Set("If-Range", s.validator)
+		}
+	}
+
+	handler := s.client.buildMiddlewareChain(s.cfg.middlewares...)
+	resp, err := handler(ctx, req)
+	if err != nil {
+		return handlerError(s.ctx, err)
+	}
+	statusCode = resp.StatusCode
+
+	// Errors are returned as a payOS JSON response, even with a 2xx status
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 || strings.Contains(resp.Header.Get("Content-Type"), "application/json") {
+		defer resp.Body.Close()
+		respBody, _ := io.ReadAll(resp.Body)
+		s.cfg.captureResponse(resp, respBody)
+		s.cfg.captureRaw(resp, respBody, s.attempt)
+
+		var apiResp PayOSResponseType
+		if err := json.Unmarshal(respBody, &apiResp); err == nil {
+			code = apiResp.Code
+			return apierror.GenerateError(resp.StatusCode, apiResp.Code, apiResp.Desc, resp.Header)
+		}
+		return apierror.GenerateError(resp.StatusCode, "", string(respBody), resp.Header)
+	}
+	s.cfg.captureResponse(resp, nil)
+	s.cfg.captureRaw(resp, nil, s.attempt)
+
+	if s.read == 0 {
+		s.setFileInfo(resp)
+	} else if err := s.skipToOffset(resp); err != nil {
+		resp.Body.Close()
+		return err
+	}
+
+	s.body = resp.Body
+	return nil
+}
+
+// setFileInfo reads the file metadata from the first response
+func (s *FileStream) setFileInfo(resp *http.Response) {
+	s.Filename = parseFilename(resp.Header.Get("Content-Disposition"))
+	s.ContentType = resp.Header.Get("Content-Type")
+	if s.ContentType == "" {
+		s.ContentType = "application/octet-stream"
+	}
+	s.Size = nil
+	if resp.ContentLength >= 0 {
+		size := resp.ContentLength
+		s.Size = &size
+	}
+
+	s.validator = responseValidator(resp)
+}
+
+// responseValidator returns the ETag of a response, or its Last-Modified date without one
+func responseValidator(resp *http.Response) string {
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		return etag
+	}
+	return resp.Header.Get("Last-Modified")
+}
+
+// skipToOffset positions a resumed response at the number of bytes already read
+// A server that ignores the Range header sends the whole file again, which is only
+// skipped when its validator matches; a 200 to an If-Range request means the file changed
+// and the bytes already returned cannot be taken back
+func (s *FileStream) skipToOffset(resp *http.Response) error {
+	if resp.StatusCode != http.StatusPartialContent {
+		if s.validator == "" || responseValidator(resp) != s.validator {
+			return apierror.NewPayOSError(fmt.Sprintf("the file changed while resuming the download at byte %d", s.read))
+		}
+		if _, err := io.CopyN(io.Discard, resp.Body, s.read); err != nil {
+			return apierror.NewConnectionError("failed to skip downloaded bytes", err)
+		}
+		return nil
+	}
+
+	start, ok := parseContentRangeStart(resp.Header.Get("Content-Range"))
+	if !ok || start != s.read {
+		return apierror.NewPayOSError(fmt.Sprintf("unexpected Content-Range %q when resuming at byte %d", resp.Header.Get("Content-Range"), s.read))
+	}
+	return nil
+}
+
+// parseContentRangeStart returns the first byte position of a Content-Range header
+func parseContentRangeStart(contentRange string) (int64, bool) {
+	rangeSpec, ok := strings.CutPrefix(contentRange, "bytes ")
+	if !ok {
+		return 0, false
+	}
+	startStr, _, ok := strings.Cut(rangeSpec, "-")
+	if !ok {
+		return 0, false
+	}
+	start, err := strconv.ParseInt(startStr, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return start, true
+}
+
+// parseFilename extracts the filename of a Content-Disposition header
+// RFC 5987 filename* parameters are decoded and take precedence over filename
+func parseFilename(contentDisposition string) *string {
+	if contentDisposition == "" {
+		return nil
+	}
+	_, params, err := mime.ParseMediaType(contentDisposition)
+	if err != nil {
+		return nil
+	}
+	filename, ok := params["filename"]
+	if !ok || filename == "" {
+		return nil
+	}
+	return &filename
+}
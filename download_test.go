@@ -0,0 +1,242 @@
+package payos
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/payOSHQ/payos-lib-golang/v2/payostest"
+)
+
+// newTestInvoice creates a paid payment link and returns its first invoice ID
+func newTestInvoice(t *testing.T, client *PayOS, srv *payostest.Server, orderCode int64) string {
+	t.Helper()
+	ctx := context.Background()
+
+	if _, err := client.PaymentRequests.Create(ctx, testPaymentLinkRequest(orderCode)); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := srv.MarkPaid(orderCode); err != nil {
+		t.Fatalf("MarkPaid() error = %v", err)
+	}
+	info, err := client.PaymentRequests.Invoices.Get(ctx, orderCode)
+	if err != nil {
+		t.Fatalf("Invoices.Get() error = %v", err)
+	}
+	if len(info.Invoices) == 0 {
+		t.Fatal("Invoices.Get() returned no invoices")
+	}
+	return info.Invoices[0].InvoiceId
+}
+
+// failingBody returns an error after limit bytes have been read
+type failingBody struct {
+	io.ReadCloser
+	limit int
+}
+
+func (b *failingBody) Read(p []byte) (int, error) {
+	if b.limit <= 0 {
+		return 0, errors.New("connection reset")
+	}
+	if len(p) > b.limit {
+		p = p[:b.limit]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.limit -= n
+	return n, err
+}
+
+func TestInvoicesDownloadTo(t *testing.T) {
+	client, srv := newTestPayOS(t)
+	invoiceId := newTestInvoice(t, client, srv, 501)
+
+	var buf bytes.Buffer
+	info, err := client.PaymentRequests.Invoices.DownloadTo(context.Background(), &buf, invoiceId, 501)
+	if err != nil {
+		t.Fatalf("DownloadTo() error = %v", err)
+	}
+	want := payostest.InvoicePDF(invoiceId)
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("DownloadTo() wrote %q, want %q", buf.Bytes(), want)
+	}
+	if info.Filename == nil || *info.Filename != invoiceId+".pdf" {
+		t.Errorf("Filename = %v", info.Filename)
+	}
+	if info.ContentType != "application/pdf" || info.Size == nil || *info.Size != int64(len(want)) {
+		t.Errorf("FileInfo = %+v", info)
+	}
+
+	file, err := client.PaymentRequests.Invoices.Download(context.Background(), invoiceId, 501)
+	if err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	if !bytes.Equal(file.Data, want) {
+		t.Errorf("Download() data = %q, want %q", file.Data, want)
+	}
+}
+
+func TestInvoicesStreamReadAfterClose(t *testing.T) {
+	client, srv := newTestPayOS(t)
+	invoiceId := newTestInvoice(t, client, srv, 506)
+
+	stream, err := client.PaymentRequests.Invoices.Stream(context.Background(), invoiceId, 506)
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	if err := stream.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if n, err := stream.Read(make([]byte, 16)); n != 0 || !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Read() after Close = %d, %v, want os.ErrClosed", n, err)
+	}
+	if err := stream.Close(); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+}
+
+func TestInvoicesStreamResume(t *testing.T) {
+	client, srv := newTestPayOS(t)
+	invoiceId := newTestInvoice(t, client, srv, 502)
+
+	var mu sync.Mutex
+	var ranges []string
+	interrupt := func(next RequestHandler) RequestHandler {
+		return func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			resp, err := next(ctx, req)
+			if err != nil {
+				return resp, err
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			ranges = append(ranges, req.Header.Get("Range"))
+			if len(ranges) == 1 {
+				resp.Body = &failingBody{ReadCloser: resp.Body, limit: 10}
+			}
+			return resp, nil
+		}
+	}
+
+	stream, err := client.PaymentRequests.Invoices.Stream(context.Background(), invoiceId, 502, WithMiddleware(interrupt))
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	defer stream.Close()
+
+	data, err := io.ReadAll(stream)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if want := payostest.InvoicePDF(invoiceId); !bytes.Equal(data, want) {
+		t.Errorf("Stream() data = %q, want %q", data, want)
+	}
+	if len(ranges) != 2 || ranges[0] != "" || ranges[1] != "bytes=10-" {
+		t.Errorf("Range headers = %q", ranges)
+	}
+}
+
+func TestInvoicesStreamResumeFullResponse(t *testing.T) {
+	client, srv := newTestPayOS(t)
+	invoiceId := newTestInvoice(t, client, srv, 504)
+
+	// ignoreRange interrupts the first response and answers the resume with the whole file and etag
+	ignoreRange := func(etag string) Middleware {
+		return func(next RequestHandler) RequestHandler {
+			return func(ctx context.Context, req *http.Request) (*http.Response, error) {
+				resumed := req.Header.Get("Range") != ""
+				req.Header.Del("Range")
+				resp, err := next(ctx, req)
+				if err != nil {
+					return resp, err
+				}
+				if resumed {
+					resp.Header.Set("ETag", etag)
+				} else {
+					resp.Body = &failingBody{ReadCloser: resp.Body, limit: 10}
+				}
+				return resp, nil
+			}
+		}
+	}
+	read := func(etag string) ([]byte, error) {
+		stream, err := client.PaymentRequests.Invoices.Stream(context.Background(), invoiceId, 504, WithMiddleware(ignoreRange(etag)))
+		if err != nil {
+			t.Fatalf("Stream() error = %v", err)
+		}
+		defer stream.Close()
+		return io.ReadAll(stream)
+	}
+
+	data, err := read(fmt.Sprintf("%q", invoiceId))
+	if err != nil {
+		t.Fatalf("ReadAll() with the same ETag error = %v", err)
+	}
+	if want := payostest.InvoicePDF(invoiceId); !bytes.Equal(data, want) {
+		t.Errorf("Stream() data = %q, want %q", data, want)
+	}
+
+	data, err = read(`"changed"`)
+	if err == nil || !strings.Contains(err.Error(), "file changed") {
+		t.Errorf("ReadAll() with a changed ETag error = %v, want the file changed", err)
+	}
+	if len(data) != 10 {
+		t.Errorf("ReadAll() returned %d bytes, want only the 10 bytes of the first response", len(data))
+	}
+}
+
+func TestInvoicesStreamNoRetries(t *testing.T) {
+	client, srv := newTestPayOS(t)
+	invoiceId := newTestInvoice(t, client, srv, 503)
+
+	interrupt := func(next RequestHandler) RequestHandler {
+		return func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			resp, err := next(ctx, req)
+			if err == nil {
+				resp.Body = &failingBody{ReadCloser: resp.Body, limit: 10}
+			}
+			return resp, err
+		}
+	}
+
+	stream, err := client.PaymentRequests.Invoices.Stream(context.Background(), invoiceId, 503, WithMiddleware(interrupt), WithMaxRetries(0))
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	defer stream.Close()
+
+	if _, err := io.ReadAll(stream); err == nil {
+		t.Fatal("ReadAll() expected error")
+	}
+}
+
+func TestParseFilename(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{`attachment; filename="invoice.pdf"`, "invoice.pdf"},
+		{`attachment; filename=invoice.pdf`, "invoice.pdf"},
+		{`attachment; filename="fallback.pdf"; filename*=UTF-8''h%C3%B3a%20%C4%91%C6%A1n.pdf`, "hóa đơn.pdf"},
+		{`attachment`, ""},
+		{``, ""},
+	}
+	for _, tt := range tests {
+		got := parseFilename(tt.header)
+		if tt.want == "" {
+			if got != nil {
+				t.Errorf("parseFilename(%q) = %q, want nil", tt.header, *got)
+			}
+			continue
+		}
+		if got == nil || *got != tt.want {
+			t.Errorf("parseFilename(%q) = %v, want %q", tt.header, got, tt.want)
+		}
+	}
+}
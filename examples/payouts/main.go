@@ -4,17 +4,15 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"os"
 
 	payos "github.com/payOSHQ/payos-lib-golang/v2"
 )
 
 func main() {
-	// Create PayOS client
+	// Create PayOS client with the payout channel credentials
+	// read from PAYOS_PAYOUT_CLIENT_ID, PAYOS_PAYOUT_API_KEY and PAYOS_PAYOUT_CHECKSUM_KEY
 	client, err := payos.NewPayOS(&payos.PayOSOptions{
-		ClientId:    os.Getenv("PAYOS_PAYOUT_CLIENT_ID"),
-		ApiKey:      os.Getenv("PAYOS_PAYOUT_API_KEY"),
-		ChecksumKey: os.Getenv("PAYOS_PAYOUT_CHECKSUM_KEY"),
+		Payout: &payos.ChannelOptions{},
 	})
 	if err != nil {
 		log.Fatal(err)
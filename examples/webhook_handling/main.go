@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
@@ -18,35 +17,20 @@ func main() {
 	}
 
 	// Setup webhook handler
-	http.HandleFunc("/webhook", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodPost {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			return
-		}
-
-		// Parse webhook body
-		var webhookData map[string]interface{}
-		if err := json.NewDecoder(r.Body).Decode(&webhookData); err != nil {
-			log.Printf("Failed to decode webhook: %v", err)
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
-			return
-		}
-
-		// Verify webhook data
-		verifiedData, err := client.Webhooks.VerifyData(context.Background(), webhookData)
-		if err != nil {
-			log.Printf("Failed to verify webhook: %v", err)
-			http.Error(w, "Invalid signature", http.StatusUnauthorized)
-			return
-		}
-
-		// Process verified webhook data
-		fmt.Printf("Webhook received: %+v\n", verifiedData)
-
-		// Respond to webhook
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
-	})
+	http.Handle("/webhook", client.Webhooks.Handler(&payos.WebhookHandlerOptions{
+		OnPaymentSucceeded: func(ctx context.Context, data *payos.WebhookData) error {
+			// Process verified webhook data
+			fmt.Printf("Payment succeeded: order %d, amount %d, reference %s\n", data.OrderCode, data.Amount, data.Reference)
+			return nil
+		},
+		OnPaymentFailed: func(ctx context.Context, data *payos.WebhookData) error {
+			fmt.Printf("Payment failed: order %d, %s\n", data.OrderCode, data.Desc)
+			return nil
+		},
+		OnError: func(r *http.Request, err error) {
+			log.Printf("Failed to handle webhook: %v", err)
+		},
+	}))
 
 	// Start server
 	fmt.Println("Webhook server listening on :8080")
@@ -0,0 +1,53 @@
+package payos
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/payOSHQ/payos-lib-golang/v2/apierror"
+	"github.com/payOSHQ/payos-lib-golang/v2/internal/crypto"
+)
+
+// IdempotencyKeyGenerator generates the x-idempotency-key of payout requests
+// It is called once per Payouts.Create or Batch.Create call and the key is reused across retries
+type IdempotencyKeyGenerator interface {
+	// GenerateIdempotencyKey returns the key for a PayoutRequest or PayoutBatchRequest body
+	GenerateIdempotencyKey(ctx context.Context, body interface{}) (string, error)
+}
+
+// IdempotencyKeyGeneratorFunc adapts a function to an IdempotencyKeyGenerator
+type IdempotencyKeyGeneratorFunc func(ctx context.Context, body interface{}) (string, error)
+
+// GenerateIdempotencyKey calls f(ctx, body)
+func (f IdempotencyKeyGeneratorFunc) GenerateIdempotencyKey(ctx context.Context, body interface{}) (string, error) {
+	return f(ctx, body)
+}
+
+// UUIDKeyGenerator generates random UUID version 4 keys from crypto/rand
+// It is the default IdempotencyKeyGenerator
+type UUIDKeyGenerator struct{}
+
+// GenerateIdempotencyKey returns a new random UUID
+func (UUIDKeyGenerator) GenerateIdempotencyKey(ctx context.Context, body interface{}) (string, error) {
+	return crypto.GenerateUUID()
+}
+
+// resolveIdempotencyKey returns the idempotency key of a payout request
+// WithIdempotencyKey takes precedence over key, and the generator is only called when neither is set
+func (c *Client) resolveIdempotencyKey(ctx context.Context, key *string, body interface{}, opts []RequestOption) (string, error) {
+	if cfgKey := c.newRequestConfig(opts).idempotencyKey; cfgKey != "" {
+		return cfgKey, nil
+	}
+	if key != nil {
+		return *key, nil
+	}
+
+	generated, err := c.idempotencyKeyGenerator.GenerateIdempotencyKey(ctx, body)
+	if err != nil {
+		return "", apierror.NewPayOSError(fmt.Sprintf("failed to generate idempotency key: %v", err))
+	}
+	if generated == "" {
+		return "", apierror.NewPayOSError("idempotency key generator returned an empty key")
+	}
+	return generated, nil
+}
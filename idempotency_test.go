@@ -0,0 +1,65 @@
+package payos
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestIdempotencyKeyGenerator(t *testing.T) {
+	client, srv := newTestPayOS(t)
+	ctx := context.Background()
+
+	byReference := IdempotencyKeyGeneratorFunc(func(ctx context.Context, body interface{}) (string, error) {
+		payout, ok := body.(PayoutRequest)
+		if !ok {
+			return "", errors.New("unexpected body")
+		}
+		return "payout-" + payout.ReferenceId, nil
+	})
+
+	// A restarted worker builds a new client but derives the same key
+	newWorker := func() *PayOS {
+		worker, err := NewPayOS(&PayOSOptions{
+			ClientId:                srv.ClientId,
+			ApiKey:                  srv.ApiKey,
+			ChecksumKey:             srv.ChecksumKey,
+			BaseURL:                 srv.URL,
+			IdempotencyKeyGenerator: byReference,
+		})
+		if err != nil {
+			t.Fatalf("NewPayOS() error = %v", err)
+		}
+		return worker
+	}
+
+	first, err := newWorker().Payouts.Create(ctx, testPayoutRequest("order-1"), nil)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	second, err := newWorker().Payouts.Create(ctx, testPayoutRequest("order-1"), nil)
+	if err != nil {
+		t.Fatalf("Create() replay error = %v", err)
+	}
+	if first.Id != second.Id {
+		t.Errorf("Create() replay id = %s, want %s", second.Id, first.Id)
+	}
+
+	// The batch body is not a PayoutRequest so the generator fails
+	if _, err := newWorker().Payouts.Batch.Create(ctx, PayoutBatchRequest{ReferenceId: "batch-1"}, nil); err == nil {
+		t.Error("Batch.Create() expected generator error")
+	}
+
+	// The default generator returns a different key for every call
+	a, err := client.Payouts.Create(ctx, testPayoutRequest("order-2"), nil)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	b, err := client.Payouts.Create(ctx, testPayoutRequest("order-2"), nil)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if a.Id == b.Id {
+		t.Errorf("default generator reused the idempotency key")
+	}
+}
@@ -1,18 +0,0 @@
-package apierror
-
-import "testing"
-
-func TestAPIError(t *testing.T) {
-	// TODO: implement test
-	t.Skip("Test implementation pending")
-}
-
-func TestAPIErrorHandling(t *testing.T) {
-	// TODO: implement test
-	t.Skip("Test implementation pending")
-}
-
-func TestValidationErrors(t *testing.T) {
-	// TODO: implement test
-	t.Skip("Test implementation pending")
-}
@@ -1,8 +1,10 @@
 package crypto
 
 import (
+	"bytes"
 	"crypto/hmac"
 	"crypto/md5"
+	"crypto/rand"
 	"crypto/sha1"
 	"crypto/sha256"
 	"crypto/sha512"
@@ -11,11 +13,9 @@ import (
 	"fmt"
 	"hash"
 	"net/url"
-	"reflect"
 	"sort"
 	"strconv"
 	"strings"
-	"time"
 )
 
 // CreateSignatureFromObj creates a signature from an object by sorting keys
@@ -53,8 +53,19 @@ func CreateSignature(key string, data interface{}, options *SignatureOptions) (s
 			Algorithm:  "sha256",
 		}
 	}
-	if options.Algorithm == "" {
-		options.Algorithm = "sha256"
+
+	queryString, err := CanonicalQueryString(data, options)
+	if err != nil {
+		return "", err
+	}
+
+	return HMAC(options.Algorithm, key, queryString)
+}
+
+// CanonicalQueryString builds the sorted query string signed by CreateSignature
+func CanonicalQueryString(data interface{}, options *SignatureOptions) (string, error) {
+	if options == nil {
+		options = &SignatureOptions{EncodeURI: true}
 	}
 
 	// Deep sort the data
@@ -116,12 +127,15 @@ func CreateSignature(key string, data interface{}, options *SignatureOptions) (s
 		}
 	}
 
-	queryString := strings.Join(pairs, "&")
+	return strings.Join(pairs, "&"), nil
+}
 
-	// Create HMAC with specified algorithm
+// HMAC computes the hex encoded HMAC of data with the given algorithm
+// Supported algorithms are "sha256" (default), "sha1", "sha512" and "md5"
+func HMAC(algorithm string, key string, data string) (string, error) {
 	var hasher hash.Hash
-	switch options.Algorithm {
-	case "sha256":
+	switch algorithm {
+	case "sha256", "":
 		hasher = hmac.New(sha256.New, []byte(key))
 	case "sha1":
 		hasher = hmac.New(sha1.New, []byte(key))
@@ -130,64 +144,60 @@ func CreateSignature(key string, data interface{}, options *SignatureOptions) (s
 	case "md5":
 		hasher = hmac.New(md5.New, []byte(key))
 	default:
-		return "", fmt.Errorf("unsupported algorithm: %s", options.Algorithm)
+		return "", fmt.Errorf("unsupported algorithm: %s", algorithm)
 	}
 
-	hasher.Write([]byte(queryString))
-	signature := hex.EncodeToString(hasher.Sum(nil))
-
-	return signature, nil
+	hasher.Write([]byte(data))
+	return hex.EncodeToString(hasher.Sum(nil)), nil
 }
 
 // CreateSignatureOfPaymentRequest creates a signature specifically for payment requests
 func CreateSignatureOfPaymentRequest(data interface{}, key string) (string, error) {
-	v := reflect.ValueOf(data)
-	if v.Kind() == reflect.Ptr {
-		v = v.Elem()
+	dataStr, err := CanonicalPaymentRequest(data)
+	if err != nil {
+		return "", err
 	}
 
-	amountField := v.FieldByName("Amount")
-	cancelUrlField := v.FieldByName("CancelUrl")
-	descriptionField := v.FieldByName("Description")
-	orderCodeField := v.FieldByName("OrderCode")
-	returnUrlField := v.FieldByName("ReturnUrl")
+	return HMAC("sha256", key, dataStr)
+}
 
-	if !amountField.IsValid() || !cancelUrlField.IsValid() || !descriptionField.IsValid() ||
-		!orderCodeField.IsValid() || !returnUrlField.IsValid() {
-		return "", fmt.Errorf("data must have Amount, CancelUrl, Description, OrderCode, and ReturnUrl fields")
+// CanonicalPaymentRequest builds the string signed by CreateSignatureOfPaymentRequest
+// Data may be any value whose JSON form has amount, cancelUrl, description, orderCode and returnUrl
+func CanonicalPaymentRequest(data interface{}) (string, error) {
+	fields, err := toObject(data)
+	if err != nil {
+		return "", err
 	}
 
-	amount := int(amountField.Int())
-	cancelUrl := cancelUrlField.String()
-	description := descriptionField.String()
-	orderCode := orderCodeField.Int()
-	returnUrl := returnUrlField.String()
-
-	dataStr := fmt.Sprintf("amount=%s&cancelUrl=%s&description=%s&orderCode=%s&returnUrl=%s",
-		strconv.Itoa(amount), cancelUrl, description, strconv.FormatInt(orderCode, 10), returnUrl)
-
-	hasher := hmac.New(sha256.New, []byte(key))
-	hasher.Write([]byte(dataStr))
-	signature := hex.EncodeToString(hasher.Sum(nil))
+	keys := []string{"amount", "cancelUrl", "description", "orderCode", "returnUrl"}
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		value, ok := fields[key]
+		if !ok {
+			return "", fmt.Errorf("data must have Amount, CancelUrl, Description, OrderCode, and ReturnUrl fields")
+		}
+		var stringValue string
+		if value != nil {
+			stringValue, err = convertToString(value)
+			if err != nil {
+				return "", err
+			}
+		}
+		pairs = append(pairs, fmt.Sprintf("%s=%s", key, stringValue))
+	}
 
-	return signature, nil
+	return strings.Join(pairs, "&"), nil
 }
 
 // SortObjByKey sorts an object's keys and returns a formatted string
 func SortObjByKey(obj interface{}) (string, error) {
-	jsonBytes, err := json.Marshal(obj)
+	jsonObj, err := toObject(obj)
 	if err != nil {
 		return "", err
 	}
 
 	var sortedPairs []string
 
-	var jsonObj map[string]interface{}
-	err = json.Unmarshal(jsonBytes, &jsonObj)
-	if err != nil {
-		return "", err
-	}
-
 	keys := make([]string, 0, len(jsonObj))
 	for key := range jsonObj {
 		keys = append(keys, key)
@@ -212,8 +222,50 @@ func SortObjByKey(obj interface{}) (string, error) {
 	return sortedObj, nil
 }
 
+// toObject converts a value to its JSON object form
+func toObject(obj interface{}) (map[string]interface{}, error) {
+	value, err := toValue(obj)
+	if err != nil {
+		return nil, err
+	}
+	jsonObj, ok := value.(map[string]interface{})
+	if !ok && value != nil {
+		return nil, fmt.Errorf("data must be an object/map")
+	}
+	return jsonObj, nil
+}
+
+// toValue converts a value to its generic JSON form
+// Raw JSON input is decoded directly, and numbers are kept as json.Number to preserve precision
+func toValue(obj interface{}) (interface{}, error) {
+	var jsonBytes []byte
+	switch v := obj.(type) {
+	case json.RawMessage:
+		jsonBytes = v
+	case []byte:
+		jsonBytes = v
+	default:
+		var err error
+		jsonBytes, err = json.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(jsonBytes))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
 func convertToString(value interface{}) (string, error) {
 	switch v := value.(type) {
+	case json.Number:
+		return v.String(), nil
 	case int64:
 		return strconv.FormatInt(v, 10), nil
 	case float64:
@@ -226,17 +278,15 @@ func convertToString(value interface{}) (string, error) {
 	}
 }
 
-func GenerateUUID() string {
-	// Simple UUID v4 generation
+// GenerateUUID returns a random UUID version 4 read from crypto/rand
+func GenerateUUID() (string, error) {
 	b := make([]byte, 16)
-	// This is a simplified version - in production, use crypto/rand
-	timestamp := time.Now().UnixNano()
-	for i := 0; i < 16; i++ {
-		b[i] = byte((timestamp >> (i * 8)) & 0xff)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to read random bytes: %w", err)
 	}
 	b[6] = (b[6] & 0x0f) | 0x40 // Version 4
 	b[8] = (b[8] & 0x3f) | 0x80 // Variant bits
-	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
 }
 
 // deepSortObject recursively sorts an object's keys
@@ -270,16 +320,18 @@ func deepSortObject(data interface{}, sortArrays bool) (interface{}, error) {
 			})
 		}
 		return result, nil
+	case nil, string, bool, json.Number:
+		return v, nil
 	default:
-		// For other types, just marshal and unmarshal to normalize
-		jsonBytes, err := json.Marshal(v)
+		// For other types, normalize through JSON keeping numbers as json.Number
+		// so they are formatted exactly like their JSON literal
+		result, err := toValue(v)
 		if err != nil {
 			return nil, err
 		}
-		var result interface{}
-		err = json.Unmarshal(jsonBytes, &result)
-		if err != nil {
-			return v, nil // Return original if unmarshal fails
+		switch result.(type) {
+		case map[string]interface{}, []interface{}:
+			return deepSortObject(result, sortArrays)
 		}
 		return result, nil
 	}
@@ -1,6 +1,9 @@
 package crypto
 
-import "testing"
+import (
+	"regexp"
+	"testing"
+)
 
 func TestCrypto(t *testing.T) {
 	// TODO: implement test
@@ -16,3 +19,21 @@ func TestSignatureVerification(t *testing.T) {
 	// TODO: implement test
 	t.Skip("Test implementation pending")
 }
+
+func TestGenerateUUID(t *testing.T) {
+	pattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		uuid, err := GenerateUUID()
+		if err != nil {
+			t.Fatalf("GenerateUUID() error = %v", err)
+		}
+		if !pattern.MatchString(uuid) {
+			t.Fatalf("GenerateUUID() = %q, not a UUID v4", uuid)
+		}
+		if seen[uuid] {
+			t.Fatalf("GenerateUUID() returned duplicate %q", uuid)
+		}
+		seen[uuid] = true
+	}
+}
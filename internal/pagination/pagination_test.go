@@ -1,18 +0,0 @@
-package pagination
-
-import "testing"
-
-func TestPagination(t *testing.T) {
-	// TODO: implement test
-	t.Skip("Test implementation pending")
-}
-
-func TestPaginationIterator(t *testing.T) {
-	// TODO: implement test
-	t.Skip("Test implementation pending")
-}
-
-func TestAutoPagination(t *testing.T) {
-	// TODO: implement test
-	t.Skip("Test implementation pending")
-}
@@ -3,9 +3,9 @@ package payos
 import (
 	"context"
 	"fmt"
+	"io"
 
-	"github.com/payOSHQ/payos-lib-golang/v2/internal/apierror"
-	"github.com/payOSHQ/payos-lib-golang/v2/internal/apijson"
+	"github.com/payOSHQ/payos-lib-golang/v2/apierror"
 )
 
 // Invoices handles invoice operations for payment links
@@ -21,7 +21,8 @@ func newInvoices(client *Client) *Invoices {
 }
 
 // Get retrieves invoices of a payment link by payment link ID or order code
-func (inv *Invoices) Get(ctx context.Context, id interface{}) (*InvoicesInfo, error) {
+func (inv *Invoices) Get(ctx context.Context, id interface{}, opts ...RequestOption) (*InvoicesInfo, error) {
+	opts = withOperation("Invoices.Get", opts)
 	var idStr string
 	switch v := id.(type) {
 	case string:
@@ -38,22 +39,57 @@ func (inv *Invoices) Get(ctx context.Context, id interface{}) (*InvoicesInfo, er
 		return nil, apierror.NewPayOSError("invalid params")
 	}
 
-	path := fmt.Sprintf("/v2/payment-requests/%s/invoices", id)
-	result, err := inv.client.Get(ctx, path, nil, nil, &SignatureOpts{Response: "body"})
+	path := fmt.Sprintf("/v2/payment-requests/%s/invoices", idStr)
+	return Do[InvoicesInfo](ctx, inv.client, &RequestOptions{
+		Method:        "GET",
+		Path:          path,
+		SignatureOpts: &SignatureOpts{Response: "body"},
+	}, opts...)
+}
+
+// Download downloads an invoice in PDF format by invoice ID and payment link ID or order code
+func (inv *Invoices) Download(ctx context.Context, invoiceId string, id interface{}, opts ...RequestOption) (*FileDownloadResponse, error) {
+	opts = withOperation("Invoices.Download", opts)
+	path, err := invoiceDownloadPath(invoiceId, id)
+	if err != nil {
+		return nil, err
+	}
+	return inv.client.DownloadFile(ctx, path, opts...)
+}
+
+// Stream opens a streaming download of an invoice in PDF format
+// The caller must close the returned stream
+func (inv *Invoices) Stream(ctx context.Context, invoiceId string, id interface{}, opts ...RequestOption) (*FileStream, error) {
+	opts = withOperation("Invoices.Stream", opts)
+	path, err := invoiceDownloadPath(invoiceId, id)
+	if err != nil {
+		return nil, err
+	}
+	return inv.client.DownloadStream(ctx, path, opts...)
+}
+
+// DownloadTo streams an invoice in PDF format into w
+// The returned Size is the number of bytes written
+func (inv *Invoices) DownloadTo(ctx context.Context, w io.Writer, invoiceId string, id interface{}, opts ...RequestOption) (*FileInfo, error) {
+	opts = withOperation("Invoices.DownloadTo", opts)
+	stream, err := inv.Stream(ctx, invoiceId, id, opts...)
 	if err != nil {
 		return nil, err
 	}
+	defer stream.Close()
 
-	var response InvoicesInfo
-	if err := apijson.ConvertInterface(result, &response); err != nil {
-		return nil, apierror.NewPayOSError("failed to parse response")
+	written, err := io.Copy(w, stream)
+	if err != nil {
+		return nil, err
 	}
 
-	return &response, nil
+	info := stream.FileInfo
+	info.Size = &written
+	return &info, nil
 }
 
-// Download downloads an invoice in PDF format by invoice ID and payment link ID or order code
-func (inv *Invoices) Download(ctx context.Context, invoiceId string, id interface{}) (*FileDownloadResponse, error) {
+// invoiceDownloadPath builds the download path of an invoice
+func invoiceDownloadPath(invoiceId string, id interface{}) (string, error) {
 	var idStr string
 	switch v := id.(type) {
 	case string:
@@ -63,13 +99,12 @@ func (inv *Invoices) Download(ctx context.Context, invoiceId string, id interfac
 	case int64:
 		idStr = fmt.Sprintf("%d", v)
 	default:
-		return nil, apierror.NewPayOSError("id must be string or number")
+		return "", apierror.NewPayOSError("id must be string or number")
 	}
 
 	if invoiceId == "" || idStr == "" {
-		return nil, apierror.NewPayOSError("invalid params")
+		return "", apierror.NewPayOSError("invalid params")
 	}
 
-	path := fmt.Sprintf("/v2/payment-requests/%s/invoices/%s/download", idStr, invoiceId)
-	return inv.client.DownloadFile(ctx, path)
+	return fmt.Sprintf("/v2/payment-requests/%s/invoices/%s/download", idStr, invoiceId), nil
 }
@@ -0,0 +1,235 @@
+package payos
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// DefaultRedactHeaders are the headers logged as [REDACTED] when LoggerOptions.RedactHeaders is nil
+var DefaultRedactHeaders = []string{"x-client-id", "x-api-key", "authorization", "cookie", "set-cookie"}
+
+// DefaultMaskFields are the JSON fields masked in logged bodies when LoggerOptions.MaskFields is nil
+var DefaultMaskFields = []string{
+	"buyerName", "buyerCompanyName", "buyerTaxCode", "buyerEmail", "buyerPhone", "buyerAddress",
+	"toAccountNumber", "accountNumber", "accountName",
+	"counterAccountName", "counterAccountNumber", "virtualAccountName", "virtualAccountNumber",
+}
+
+const redacted = "[REDACTED]"
+
+// LoggerOptions configures the structured request logging of PayOSOptions.Logger
+type LoggerOptions struct {
+	// RedactHeaders lists the headers whose values are replaced with [REDACTED]
+	// Defaults to DefaultRedactHeaders
+	RedactHeaders []string
+
+	// MaskFields lists the JSON fields whose values are masked in logged bodies
+	// Matching is case-insensitive and applies at any depth
+	// Defaults to DefaultMaskFields
+	MaskFields []string
+}
+
+type attemptKey struct{}
+
+// withAttempt returns a context carrying the zero-based attempt number of a request
+func withAttempt(ctx context.Context, attempt int) context.Context {
+	return context.WithValue(ctx, attemptKey{}, attempt)
+}
+
+// AttemptFromContext returns the zero-based attempt number of the request handled by a middleware
+func AttemptFromContext(ctx context.Context) int {
+	attempt, _ := ctx.Value(attemptKey{}).(int)
+	return attempt
+}
+
+// createLoggerMiddleware creates a middleware that logs every HTTP attempt to logger
+// A summary is logged at info level, headers and bodies only at debug level
+func createLoggerMiddleware(logger *slog.Logger, opts *LoggerOptions, clock Clock) Middleware {
+	if opts == nil {
+		opts = &LoggerOptions{}
+	}
+	redactHeaders := make(map[string]bool)
+	for _, h := range getSliceValue(opts.RedactHeaders, DefaultRedactHeaders) {
+		redactHeaders[http.CanonicalHeaderKey(h)] = true
+	}
+	maskFields := make(map[string]bool)
+	for _, f := range getSliceValue(opts.MaskFields, DefaultMaskFields) {
+		maskFields[strings.ToLower(f)] = true
+	}
+
+	return func(next RequestHandler) RequestHandler {
+		return func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			debug := logger.Enabled(ctx, slog.LevelDebug)
+			attrs := []slog.Attr{
+				slog.String("method", req.Method),
+				slog.String("path", req.URL.Path),
+				slog.Int("attempt", AttemptFromContext(ctx)),
+			}
+
+			if debug {
+				reqAttrs := []slog.Attr{
+					slog.String("query", req.URL.RawQuery),
+					slog.Any("headers", redactHeaderValues(req.Header, redactHeaders)),
+				}
+				if req.GetBody != nil {
+					if body, err := req.GetBody(); err == nil {
+						data, _ := io.ReadAll(body)
+						body.Close()
+						reqAttrs = append(reqAttrs, slog.String("body", maskJSON(data, maskFields)))
+					}
+				}
+				logger.LogAttrs(ctx, slog.LevelDebug, "payos request", append(attrs, reqAttrs...)...)
+			}
+
+			start := clock.Now()
+			resp, err := next(ctx, req)
+			attrs = append(attrs, slog.Duration("latency", clock.Now().Sub(start)))
+
+			if err != nil {
+				logger.LogAttrs(ctx, slog.LevelError, "payos request failed", append(attrs, slog.String("error", err.Error()))...)
+				return resp, err
+			}
+			attrs = append(attrs, slog.Int("status", resp.StatusCode))
+
+			// Buffer JSON bodies to read the payOS code; file downloads keep streaming
+			var respBody []byte
+			if strings.Contains(resp.Header.Get("Content-Type"), "json") {
+				respBody, err = io.ReadAll(resp.Body)
+				resp.Body.Close()
+				resp.Body = io.NopCloser(bytes.NewReader(respBody))
+				if err != nil {
+					logger.LogAttrs(ctx, slog.LevelError, "payos response read failed", append(attrs, slog.String("error", err.Error()))...)
+					return resp, nil
+				}
+				var envelope struct {
+					Code string `json:"code"`
+					Desc string `json:"desc"`
+				}
+				if json.Unmarshal(respBody, &envelope) == nil && envelope.Code != "" {
+					attrs = append(attrs, slog.String("code", envelope.Code), slog.String("desc", envelope.Desc))
+				}
+			}
+
+			level := slog.LevelInfo
+			if resp.StatusCode >= 400 {
+				level = slog.LevelWarn
+			}
+			logger.LogAttrs(ctx, level, "payos response", attrs...)
+
+			if debug {
+				respAttrs := []slog.Attr{
+					slog.String("method", req.Method),
+					slog.String("path", req.URL.Path),
+					slog.Int("attempt", AttemptFromContext(ctx)),
+					slog.Any("headers", redactHeaderValues(resp.Header, redactHeaders)),
+				}
+				if respBody != nil {
+					respAttrs = append(respAttrs, slog.String("body", maskJSON(respBody, maskFields)))
+				}
+				logger.LogAttrs(ctx, slog.LevelDebug, "payos response details", respAttrs...)
+			}
+
+			return resp, nil
+		}
+	}
+}
+
+// redactHeaderValues flattens headers for logging, replacing secret values
+func redactHeaderValues(header http.Header, redact map[string]bool) map[string]string {
+	values := make(map[string]string, len(header))
+	for key, v := range header {
+		if redact[http.CanonicalHeaderKey(key)] {
+			values[key] = redacted
+			continue
+		}
+		values[key] = strings.Join(v, ", ")
+	}
+	return values
+}
+
+// maskJSON returns data with the values of the given fields masked
+// Bodies that are not valid JSON are replaced with their length
+func maskJSON(data []byte, fields map[string]bool) string {
+	if len(data) == 0 {
+		return ""
+	}
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Sprintf("[non-JSON body of %d bytes omitted]", len(data))
+	}
+	masked, err := json.Marshal(maskValue(value, fields))
+	if err != nil {
+		return "[body omitted]"
+	}
+	return string(masked)
+}
+
+// maskValue recursively masks the values of the given fields
+func maskValue(value interface{}, fields map[string]bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if fields[strings.ToLower(key)] {
+				v[key] = maskString(item)
+				continue
+			}
+			v[key] = maskValue(item, fields)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = maskValue(item, fields)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// maskString masks a value, keeping the last 4 characters of long strings
+func maskString(value interface{}) interface{} {
+	s, ok := value.(string)
+	if !ok {
+		if value == nil {
+			return nil
+		}
+		return "****"
+	}
+	runes := []rune(s)
+	if len(runes) <= 4 {
+		return strings.Repeat("*", len(runes))
+	}
+	return strings.Repeat("*", len(runes)-4) + string(runes[len(runes)-4:])
+}
+
+// logWriter writes slog records through a *log.Logger to keep its prefix and flags
+type logWriter struct {
+	logger *log.Logger
+}
+
+func (w logWriter) Write(p []byte) (int, error) {
+	w.logger.Print(string(p))
+	return len(p), nil
+}
+
+// newDebugSlogLogger adapts the legacy DebugLogger option to a debug level slog logger
+func newDebugSlogLogger(logger *log.Logger) *slog.Logger {
+	return slog.New(slog.NewTextHandler(logWriter{logger: logger}, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+// getSliceValue returns values, or defaults when values is nil
+func getSliceValue(values, defaults []string) []string {
+	if values == nil {
+		return defaults
+	}
+	return values
+}
@@ -0,0 +1,148 @@
+package payos
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/payOSHQ/payos-lib-golang/v2/payostest"
+)
+
+// newLoggedTestPayOS returns a client against a fake server logging into the returned buffer
+func newLoggedTestPayOS(t *testing.T, opts *PayOSOptions) (*PayOS, *bytes.Buffer) {
+	t.Helper()
+	_, srv := newTestPayOS(t)
+
+	opts.ClientId = srv.ClientId
+	opts.ApiKey = srv.ApiKey
+	opts.ChecksumKey = srv.ChecksumKey
+	opts.BaseURL = srv.URL
+	var buf bytes.Buffer
+	if opts.Logger == nil && opts.DebugLogger == nil {
+		opts.Logger = slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	}
+	if opts.DebugLogger != nil {
+		opts.DebugLogger.SetOutput(&buf)
+	}
+
+	client, err := NewPayOS(opts)
+	if err != nil {
+		t.Fatalf("NewPayOS() error = %v", err)
+	}
+	return client, &buf
+}
+
+func TestLogger(t *testing.T) {
+	client, buf := newLoggedTestPayOS(t, &PayOSOptions{})
+
+	phone := "0912345678"
+	req := testPaymentLinkRequest(701)
+	req.BuyerPhone = &phone
+	if _, err := client.PaymentRequests.Create(context.Background(), req); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	out := buf.String()
+	for _, secret := range []string{"payostest-api-key", "payostest-client-id", phone} {
+		if strings.Contains(out, secret) {
+			t.Errorf("log output contains %q:\n%s", secret, out)
+		}
+	}
+	if !strings.Contains(out, "******5678") {
+		t.Errorf("log output does not contain the masked phone:\n%s", out)
+	}
+
+	var summary map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("invalid log line %q: %v", line, err)
+		}
+		if record["msg"] == "payos response" {
+			summary = record
+		}
+	}
+	if summary == nil {
+		t.Fatalf("no payos response record:\n%s", out)
+	}
+	if summary["level"] != "INFO" || summary["method"] != "POST" || summary["path"] != "/v2/payment-requests" ||
+		summary["status"] != float64(200) || summary["code"] != "00" || summary["attempt"] != float64(0) {
+		t.Errorf("payos response record = %v", summary)
+	}
+	if _, ok := summary["latency"]; !ok {
+		t.Errorf("payos response record has no latency: %v", summary)
+	}
+}
+
+func TestLoggerClock(t *testing.T) {
+	var buf bytes.Buffer
+	clock := payostest.NewFakeClock(time.Now())
+	slow := func(next RequestHandler) RequestHandler {
+		return func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			clock.Advance(1500 * time.Millisecond)
+			return next(ctx, req)
+		}
+	}
+	client, _ := newLoggedTestPayOS(t, &PayOSOptions{
+		Logger:      slog.New(slog.NewJSONHandler(&buf, nil)),
+		Clock:       clock,
+		Middlewares: []Middleware{slow},
+	})
+
+	if _, err := client.PaymentRequests.Create(context.Background(), testPaymentLinkRequest(704)); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("invalid log output %q: %v", buf.String(), err)
+	}
+	if record["latency"] != float64(1500*time.Millisecond) {
+		t.Errorf("latency = %v, want the 1.5s of the fake clock", record["latency"])
+	}
+}
+
+func TestLoggerInfoLevel(t *testing.T) {
+	var buf bytes.Buffer
+	client, _ := newLoggedTestPayOS(t, &PayOSOptions{
+		Logger: slog.New(slog.NewJSONHandler(&buf, nil)),
+	})
+
+	if _, err := client.PaymentRequests.Create(context.Background(), testPaymentLinkRequest(702)); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if out := buf.String(); strings.Contains(out, "body") || strings.Contains(out, "headers") || !strings.Contains(out, "payos response") {
+		t.Errorf("info level output:\n%s", out)
+	}
+}
+
+func TestDebugLoggerShim(t *testing.T) {
+	client, buf := newLoggedTestPayOS(t, &PayOSOptions{
+		DebugLogger: log.New(nil, "[payOS] ", 0),
+	})
+
+	if _, err := client.PaymentRequests.Create(context.Background(), testPaymentLinkRequest(703)); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "[payOS] ") || !strings.Contains(out, redacted) || strings.Contains(out, "payostest-api-key") {
+		t.Errorf("debug logger output:\n%s", out)
+	}
+}
+
+func TestMaskJSON(t *testing.T) {
+	fields := map[string]bool{"toaccountnumber": true, "buyeremail": true}
+	got := maskJSON([]byte(`{"amount":1000000,"toAccountNumber":"0123456789","items":[{"buyerEmail":"a@b.vn"}]}`), fields)
+	want := `{"amount":1000000,"items":[{"buyerEmail":"**b.vn"}],"toAccountNumber":"******6789"}`
+	if got != want {
+		t.Errorf("maskJSON() = %s, want %s", got, want)
+	}
+	if got := maskJSON([]byte("<html>"), fields); got != "[non-JSON body of 6 bytes omitted]" {
+		t.Errorf("maskJSON() of a non-JSON body = %s", got)
+	}
+}
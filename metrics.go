@@ -0,0 +1,128 @@
+package payos
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/payOSHQ/payos-lib-golang/v2/apierror"
+)
+
+// Webhook verification results reported in WebhookMetrics
+const (
+	WebhookResultVerified         = "verified"
+	WebhookResultDuplicate        = "duplicate"
+	WebhookResultInvalidSignature = "invalid_signature"
+	WebhookResultInvalid          = "invalid"
+)
+
+// Metrics records the activity of a client, see PayOSOptions.Metrics
+// Implementations must be safe for concurrent use
+type Metrics interface {
+	// ObserveAttempt is called after every HTTP attempt, including retries and download resumes
+	ObserveAttempt(ctx context.Context, m AttemptMetrics)
+
+	// ObserveRequest is called once per API call or download with its final outcome
+	ObserveRequest(ctx context.Context, m RequestMetrics)
+
+	// ObserveWebhook is called after every webhook verification
+	ObserveWebhook(ctx context.Context, m WebhookMetrics)
+}
+
+// AttemptMetrics describes a single HTTP attempt
+type AttemptMetrics struct {
+	// Operation names the SDK method, such as Payouts.Create
+	Operation string
+
+	Method string
+	Path   string
+
+	// Attempt is the zero-based attempt number
+	Attempt int
+
+	// StatusCode is the HTTP status, or zero if no response was received
+	StatusCode int
+
+	// Code is the payOS code of the response, if any
+	Code string
+
+	// SignatureFailure reports whether the response signature did not verify
+	SignatureFailure bool
+
+	Err     error
+	Latency time.Duration
+}
+
+// RequestMetrics describes an API call or download across all its attempts
+type RequestMetrics struct {
+	// Operation names the SDK method, such as Payouts.Create
+	Operation string
+
+	Method string
+	Path   string
+
+	// StatusCode and Code are those of the last attempt
+	StatusCode int
+	Code       string
+
+	// Retries is the number of attempts after the first one
+	Retries int
+
+	Err     error
+	Latency time.Duration
+}
+
+// WebhookMetrics describes a webhook verification
+type WebhookMetrics struct {
+	// Result is one of the WebhookResult constants
+	Result string
+
+	Err     error
+	Latency time.Duration
+}
+
+// nopMetrics is the Metrics of clients without PayOSOptions.Metrics
+type nopMetrics struct{}
+
+func (nopMetrics) ObserveAttempt(ctx context.Context, m AttemptMetrics) {}
+func (nopMetrics) ObserveRequest(ctx context.Context, m RequestMetrics) {}
+func (nopMetrics) ObserveWebhook(ctx context.Context, m WebhookMetrics) {}
+
+// observeWebhook records a webhook verification
+func (c *Client) observeWebhook(ctx context.Context, start time.Time, err error) {
+	result := WebhookResultVerified
+	switch {
+	case err == nil:
+	case errors.Is(err, apierror.ErrDuplicateWebhook):
+		result = WebhookResultDuplicate
+	case errors.Is(err, apierror.ErrInvalidSignature):
+		result = WebhookResultInvalidSignature
+	default:
+		result = WebhookResultInvalid
+	}
+	c.metrics.ObserveWebhook(ctx, WebhookMetrics{Result: result, Err: err, Latency: c.clock.Now().Sub(start)})
+}
+
+// observeAttempt records an HTTP attempt and keeps its status and code for the request metrics
+func (c *Client) observeAttempt(ctx context.Context, cfg *requestConfig, m AttemptMetrics, start time.Time) {
+	m.Operation = cfg.operation
+	m.Latency = c.clock.Now().Sub(start)
+	// A signature error after a response is a response signature failure
+	m.SignatureFailure = m.StatusCode != 0 && errors.Is(m.Err, apierror.ErrInvalidSignature)
+	cfg.lastStatus, cfg.lastCode = m.StatusCode, m.Code
+	c.metrics.ObserveAttempt(ctx, m)
+}
+
+// observeRequest records the outcome of an API call or download
+func (c *Client) observeRequest(ctx context.Context, cfg *requestConfig, method, path string, attempts int, start time.Time, err error) {
+	c.metrics.ObserveRequest(ctx, RequestMetrics{
+		Operation:  cfg.operation,
+		Method:     method,
+		Path:       path,
+		StatusCode: cfg.lastStatus,
+		Code:       cfg.lastCode,
+		Retries:    max(attempts-1, 0),
+		Err:        err,
+		Latency:    c.clock.Now().Sub(start),
+	})
+}
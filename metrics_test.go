@@ -0,0 +1,109 @@
+package payos
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/payOSHQ/payos-lib-golang/v2/payostest"
+)
+
+func TestPrometheusMetrics(t *testing.T) {
+	srv := payostest.NewServer(nil)
+	t.Cleanup(srv.Close)
+	metrics := NewPrometheusMetrics(0.5, 1)
+	client, err := NewPayOS(&PayOSOptions{
+		ClientId:    srv.ClientId,
+		ApiKey:      srv.ApiKey,
+		ChecksumKey: srv.ChecksumKey,
+		BaseURL:     srv.URL,
+		Metrics:     metrics,
+		RetryPolicy: RetryPolicyFunc(func(ctx context.Context, attempt *RetryAttempt) (time.Duration, bool) {
+			return 0, isRetryableError(attempt.Err)
+		}),
+	})
+	if err != nil {
+		t.Fatalf("NewPayOS() error = %v", err)
+	}
+	ctx := context.Background()
+
+	// The first attempt fails with a 503 and is retried
+	unavailable := func(next RequestHandler) RequestHandler {
+		return func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			if AttemptFromContext(ctx) == 0 {
+				return &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(""))}, nil
+			}
+			return next(ctx, req)
+		}
+	}
+	payout, err := client.Payouts.Create(ctx, testPayoutRequest("ref-metrics"), nil, WithMiddleware(unavailable))
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	tamper := func(next RequestHandler) RequestHandler {
+		return func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			resp, err := next(ctx, req)
+			if err == nil {
+				resp.Header.Set("x-signature", "tampered")
+			}
+			return resp, err
+		}
+	}
+	if _, err := client.Payouts.Get(ctx, payout.Id, WithMiddleware(tamper), WithMaxRetries(0)); err == nil {
+		t.Fatal("Get() with tampered signature expected error")
+	}
+
+	invoiceId := newTestInvoice(t, client, srv, 901)
+	if _, err := client.PaymentRequests.Invoices.DownloadTo(ctx, io.Discard, invoiceId, 901); err != nil {
+		t.Fatalf("DownloadTo() error = %v", err)
+	}
+
+	payload, err := srv.WebhookPayload(901)
+	if err != nil {
+		t.Fatalf("WebhookPayload() error = %v", err)
+	}
+	client.Webhooks.Verify(ctx, payload)
+	client.Webhooks.Verify(ctx, bytes.Replace(payload, []byte(`"amount":`), []byte(`"amount":1`), 1))
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	for _, want := range []string{
+		"# TYPE payos_requests_total counter",
+		`payos_requests_total{operation="Payouts.Create",method="POST",status="200",code="00"} 1`,
+		`payos_attempts_total{operation="Payouts.Create",method="POST",status="503",code=""} 1`,
+		`payos_attempts_total{operation="Payouts.Create",method="POST",status="200",code="00"} 1`,
+		`payos_retries_total{operation="Payouts.Create"} 1`,
+		`payos_signature_failures_total{operation="Payouts.Get"} 1`,
+		`payos_requests_total{operation="Invoices.DownloadTo",method="GET",status="200",code=""} 1`,
+		`payos_webhooks_total{result="verified"} 1`,
+		`payos_webhooks_total{result="invalid_signature"} 1`,
+		"# TYPE payos_request_duration_seconds histogram",
+		`payos_request_duration_seconds_bucket{operation="Payouts.Create",le="+Inf"} 1`,
+		`payos_request_duration_seconds_count{operation="Payouts.Create"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %s\n%s", want, body)
+		}
+	}
+}
+
+func TestPrometheusLabelEscaping(t *testing.T) {
+	metrics := NewPrometheusMetrics()
+	metrics.ObserveWebhook(context.Background(), WebhookMetrics{Result: "a\"b\\c\nd"})
+
+	var buf bytes.Buffer
+	metrics.WriteTo(&buf)
+	if want := `payos_webhooks_total{result="a\"b\\c\nd"} 1`; !strings.Contains(buf.String(), want) {
+		t.Errorf("WriteTo() = %s, want %s", buf.String(), want)
+	}
+}
@@ -2,6 +2,7 @@ package payos
 
 import (
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"time"
@@ -21,6 +22,23 @@ type PayOSOptions struct {
 	// Defaults to PAYOS_CHECKSUM_KEY environment variable
 	ChecksumKey string
 
+	// CredentialsProvider supplies the client ID, API key and checksum keys before every request
+	// and webhook verification, so keys can be rotated without a restart
+	// ClientId, ApiKey and ChecksumKey are ignored when it is set
+	// See NewEnvCredentials, NewPollingFileCredentials and NewCachedCredentials
+	CredentialsProvider CredentialsProvider
+
+	// Payment and Payout are the credentials of the payment and payout channels,
+	// as payOS issues a client ID, API key and checksum key per channel
+	// When either is set, payment requests, invoices and webhooks use Payment,
+	// or ClientId, ApiKey, ChecksumKey and CredentialsProvider when Payment is nil,
+	// while payouts and the payouts account only use Payout
+	// When both are nil, every resource uses ClientId, ApiKey, ChecksumKey and CredentialsProvider
+	// An empty Payout reads PAYOS_PAYOUT_CLIENT_ID, PAYOS_PAYOUT_API_KEY and PAYOS_PAYOUT_CHECKSUM_KEY
+	// Without any credentials in code, Payout also defaults to those variables when all of them are set
+	Payment *ChannelOptions
+	Payout  *ChannelOptions
+
 	// PartnerCode is an optional partner identifier
 	// Defaults to PAYOS_PARTNER_CODE environment variable
 	PartnerCode string
@@ -46,10 +64,64 @@ type PayOSOptions struct {
 	Middlewares []Middleware
 
 	// DebugLogger enables debug logging for requests and responses
-	// If set to a non-nil *log.Logger, debug logs will be written to that logger
-	// If set to nil and debug logging is desired, pass log.New() with desired output
-	// If not set (nil by default), no debug logging will occur
+	// If set to a non-nil *log.Logger, redacted debug logs will be written to that logger
+	// It is ignored when Logger is set
+	//
+	// Deprecated: use Logger with the debug level enabled instead
 	DebugLogger *log.Logger
+
+	// Logger enables structured logging of every HTTP attempt
+	// Method, path, status, latency, attempt and payOS code are logged at info level
+	// Headers and bodies are only logged when the debug level is enabled
+	Logger *slog.Logger
+
+	// LoggerOptions configures the header redaction and body masking of Logger
+	LoggerOptions *LoggerOptions
+
+	// IdempotencyKeyGenerator generates the idempotency key of payouts created without one
+	// Defaults to random UUID version 4 keys
+	IdempotencyKeyGenerator IdempotencyKeyGenerator
+
+	// WebhookDeduper makes webhook verification report redelivered webhooks
+	// with an error matching apierror.ErrDuplicateWebhook
+	WebhookDeduper *WebhookDeduper
+
+	// RetryPolicy decides which failed attempts are retried and how long to wait
+	// Defaults to DefaultRetryPolicy
+	RetryPolicy RetryPolicy
+
+	// RateLimiter throttles requests per endpoint group before they are sent
+	// A limiter can be shared by several clients using the same credentials
+	RateLimiter *RateLimiter
+
+	// Metrics records requests, attempts and webhook verifications
+	// See NewPrometheusMetrics for a Prometheus implementation
+	Metrics Metrics
+
+	// Observer traces every call with an operation span and a child span per HTTP attempt
+	// The attempt span is propagated to payOS with the traceparent header
+	Observer Observer
+
+	// Clock tells the time and waits for retry backoffs, download resumes and latency measurements
+	// Defaults to SystemClock; payostest.FakeClock runs retries without waiting
+	Clock Clock
+
+	// Jitter spreads out the retry backoffs of DefaultRetryPolicy
+	// Defaults to the global math/rand source; NewJitterSource makes backoffs reproducible
+	Jitter JitterSource
+}
+
+// ChannelOptions are the credentials of one payOS channel, see PayOSOptions.Payment and PayOSOptions.Payout
+type ChannelOptions struct {
+	// ClientId, ApiKey and ChecksumKey default to the environment variables of the channel,
+	// PAYOS_CLIENT_ID for the payment channel and PAYOS_PAYOUT_CLIENT_ID for the payout channel
+	ClientId    string
+	ApiKey      string
+	ChecksumKey string
+
+	// CredentialsProvider supplies rotating credentials of the channel
+	// ClientId, ApiKey and ChecksumKey are ignored when it is set
+	CredentialsProvider CredentialsProvider
 }
 
 // NewPayOSOptions creates a new PayOSOptions
@@ -69,7 +141,57 @@ func NewPayOSOptions(opts *PayOSOptions) *PayOSOptions {
 		Timeout:     getTimeoutValue(opts.Timeout, defaultTimeout),
 		Middlewares: opts.Middlewares,
 		DebugLogger: opts.DebugLogger,
+		Logger:      opts.Logger,
+
+		CredentialsProvider:     opts.CredentialsProvider,
+		Payment:                 newChannelOptions(opts.Payment, "PAYOS"),
+		Payout:                  newPayoutChannelOptions(opts),
+		LoggerOptions:           opts.LoggerOptions,
+		IdempotencyKeyGenerator: opts.IdempotencyKeyGenerator,
+		WebhookDeduper:          opts.WebhookDeduper,
+		RetryPolicy:             opts.RetryPolicy,
+		RateLimiter:             opts.RateLimiter,
+		Metrics:                 opts.Metrics,
+		Observer:                opts.Observer,
+		Clock:                   opts.Clock,
+		Jitter:                  opts.Jitter,
+	}
+}
+
+// newPayoutChannelOptions returns the payout channel of opts
+// The payout environment variables only make a channel on their own when every credential
+// comes from the environment, so they never replace credentials passed in code,
+// and when all of them are set, so a partial set is not configured
+func newPayoutChannelOptions(opts *PayOSOptions) *ChannelOptions {
+	if opts.Payout != nil {
+		return newChannelOptions(opts.Payout, "PAYOS_PAYOUT")
 	}
+	inCode := opts.ClientId != "" || opts.ApiKey != "" || opts.ChecksumKey != "" || opts.CredentialsProvider != nil || opts.Payment != nil
+	env := newChannelOptions(&ChannelOptions{}, "PAYOS_PAYOUT")
+	if inCode || env.ClientId == "" || env.ApiKey == "" || env.ChecksumKey == "" {
+		return nil
+	}
+	return env
+}
+
+// newChannelOptions copies opts with the empty credentials read from the environment variables of prefix
+// A nil opts stays nil
+func newChannelOptions(opts *ChannelOptions, prefix string) *ChannelOptions {
+	if opts == nil {
+		return nil
+	}
+
+	return &ChannelOptions{
+		ClientId:            getValue(opts.ClientId, os.Getenv(prefix+"_CLIENT_ID")),
+		ApiKey:              getValue(opts.ApiKey, os.Getenv(prefix+"_API_KEY")),
+		ChecksumKey:         getValue(opts.ChecksumKey, os.Getenv(prefix+"_CHECKSUM_KEY")),
+		CredentialsProvider: opts.CredentialsProvider,
+	}
+}
+
+// configured reports whether any credential of the channel is set
+func (o *ChannelOptions) configured() bool {
+	return o.ClientId != "" || o.ApiKey != "" || o.ChecksumKey != "" || o.CredentialsProvider != nil
 }
 
 // getValue returns the first non-empty string value
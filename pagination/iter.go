@@ -0,0 +1,50 @@
+//go:build go1.23
+
+package pagination
+
+import "iter"
+
+// All returns an iterator over every remaining item across pages
+// An error stops the iteration and is yielded with the zero value of T
+// All advances the same state as Next and must not be mixed with it
+func (it *PageIterator[T]) All() iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for it.Next() {
+			if !yield(it.Current(), nil) {
+				return
+			}
+		}
+		if err := it.Err(); err != nil {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
+
+// Pages returns an iterator over every remaining page
+// An error stops the iteration and is yielded with a nil page
+// Pages advances the same state as Next and must not be mixed with it
+func (it *PageIterator[T]) Pages() iter.Seq2[*Page[T], error] {
+	return func(yield func(*Page[T], error) bool) {
+		for {
+			page, err := it.nextPage()
+			if err != nil {
+				it.err = err
+				yield(nil, err)
+				return
+			}
+			if page == nil {
+				return
+			}
+
+			// Mark every item of the page as consumed
+			it.page = page
+			it.items = page.Data
+			it.idx = len(page.Data)
+
+			if !yield(page, nil) {
+				return
+			}
+		}
+	}
+}
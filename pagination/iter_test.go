@@ -0,0 +1,43 @@
+//go:build go1.23
+
+package pagination
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAll(t *testing.T) {
+	iter := NewPageIterator(context.Background(), testParams{limit: 2}, newTestFetcher(5, -1))
+
+	var items []int
+	for item, err := range iter.All() {
+		if err != nil {
+			t.Fatalf("All() error = %v", err)
+		}
+		items = append(items, item)
+	}
+	if len(items) != 5 {
+		t.Errorf("All() = %v", items)
+	}
+}
+
+func TestPages(t *testing.T) {
+	iter := NewPageIterator(context.Background(), testParams{limit: 2}, newTestFetcher(5, 2))
+
+	pages := 0
+	var lastErr error
+	for page, err := range iter.Pages() {
+		if err != nil {
+			lastErr = err
+			break
+		}
+		if len(page.Data) != 2 {
+			t.Errorf("page = %v", page.Data)
+		}
+		pages++
+	}
+	if pages != 1 || lastErr == nil {
+		t.Errorf("Pages() yielded %d pages, err = %v", pages, lastErr)
+	}
+}
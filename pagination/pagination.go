@@ -0,0 +1,182 @@
+// Package pagination provides pages and auto-paging iterators for payOS list endpoints.
+//
+// List parameters implement Params so that a page can request the next one
+// without reflection. On Go 1.23 and later, PageIterator also offers range-over-func
+// iterators through All and Pages.
+package pagination
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNoParams is returned when a page with more results has no Params to request the next page
+var ErrNoParams = errors.New("pagination: page has no params to request the next page")
+
+// Params is implemented by list parameters that support offset pagination
+type Params interface {
+	// WithOffset returns a copy of the params starting at offset
+	WithOffset(offset int) Params
+}
+
+// Fetcher fetches a single page of results for the given params
+type Fetcher[T any] func(ctx context.Context, params Params) (*Page[T], error)
+
+// PageIterator provides automatic pagination for list endpoints
+type PageIterator[T any] struct {
+	items   []T
+	idx     int
+	page    *Page[T]
+	err     error
+	fetcher Fetcher[T]
+	params  Params
+	ctx     context.Context
+}
+
+// Page represents a paginated response
+type Page[T any] struct {
+	Data       []T
+	Pagination Pagination
+	Fetcher    Fetcher[T]
+	Params     Params
+	Ctx        context.Context
+}
+
+// Pagination represents pagination information
+type Pagination struct {
+	Limit   int  `json:"limit"`
+	Offset  int  `json:"offset"`
+	Total   int  `json:"total"`
+	Count   int  `json:"count"`
+	HasMore bool `json:"hasMore"`
+}
+
+// NewPageIterator creates a new page iterator
+func NewPageIterator[T any](
+	ctx context.Context,
+	params Params,
+	fetcher Fetcher[T],
+) *PageIterator[T] {
+	return &PageIterator[T]{
+		ctx:     ctx,
+		params:  params,
+		fetcher: fetcher,
+		idx:     -1,
+	}
+}
+
+// Next advances the iterator to the next item
+func (iter *PageIterator[T]) Next() bool {
+	// Move to next item in current page
+	iter.idx++
+	if iter.idx < len(iter.items) {
+		return true
+	}
+
+	page, err := iter.nextPage()
+	if err != nil {
+		iter.err = err
+		return false
+	}
+	if page == nil {
+		return false
+	}
+
+	iter.page = page
+	iter.items = page.Data
+	iter.idx = 0
+	return len(iter.items) > 0
+}
+
+// nextPage fetches the first page, or the page following the current one
+// It returns nil when there are no more pages
+func (iter *PageIterator[T]) nextPage() (*Page[T], error) {
+	if iter.page == nil {
+		return iter.fetcher(iter.ctx, iter.params)
+	}
+
+	// Check if there are more pages
+	if !iter.page.Pagination.HasMore {
+		return nil, nil
+	}
+
+	return iter.page.GetNextPage()
+}
+
+// Current returns the current item
+func (iter *PageIterator[T]) Current() T {
+	if iter.idx >= 0 && iter.idx < len(iter.items) {
+		return iter.items[iter.idx]
+	}
+	var zero T
+	return zero
+}
+
+// Err returns any error encountered during iteration
+func (iter *PageIterator[T]) Err() error {
+	return iter.err
+}
+
+// Collect advances the iterator and returns up to max items
+// A max of zero or less collects every remaining item
+// Collect stops early with the context error if ctx is done
+func (iter *PageIterator[T]) Collect(ctx context.Context, max int) ([]T, error) {
+	var items []T
+	for max <= 0 || len(items) < max {
+		if err := ctx.Err(); err != nil {
+			return items, err
+		}
+		if !iter.Next() {
+			break
+		}
+		items = append(items, iter.Current())
+	}
+	return items, iter.Err()
+}
+
+// GetNextPage fetches the next page of results
+func (p *Page[T]) GetNextPage() (*Page[T], error) {
+	if !p.Pagination.HasMore {
+		return nil, nil
+	}
+
+	if p.Params == nil {
+		return nil, ErrNoParams
+	}
+
+	newOffset := p.Pagination.Offset + p.Pagination.Count
+	return p.Fetcher(p.Ctx, p.Params.WithOffset(newOffset))
+}
+
+// HasNextPage returns true if there are more pages available
+func (p *Page[T]) HasNextPage() bool {
+	return p.Pagination.HasMore
+}
+
+// Collect returns up to max items starting from this page and fetching the following ones
+// A max of zero or less collects every remaining item
+// Collect stops early with the context error if ctx is done
+func (p *Page[T]) Collect(ctx context.Context, max int) ([]T, error) {
+	var items []T
+	for page := p; page != nil; {
+		for _, item := range page.Data {
+			if max > 0 && len(items) >= max {
+				return items, nil
+			}
+			items = append(items, item)
+		}
+		if max > 0 && len(items) >= max {
+			return items, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return items, err
+		}
+
+		next, err := page.GetNextPage()
+		if err != nil {
+			return items, err
+		}
+		page = next
+	}
+	return items, nil
+}
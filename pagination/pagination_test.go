@@ -0,0 +1,110 @@
+package pagination
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type testParams struct {
+	offset int
+	limit  int
+}
+
+func (p testParams) WithOffset(offset int) Params {
+	p.offset = offset
+	return p
+}
+
+// newTestFetcher serves the integers [0, total) in pages of params.limit
+func newTestFetcher(total int, failAt int) Fetcher[int] {
+	var fetcher Fetcher[int]
+	fetcher = func(ctx context.Context, params Params) (*Page[int], error) {
+		p := params.(testParams)
+		if failAt >= 0 && p.offset >= failAt {
+			return nil, errors.New("fetch failed")
+		}
+		var data []int
+		for i := p.offset; i < total && i < p.offset+p.limit; i++ {
+			data = append(data, i)
+		}
+		return &Page[int]{
+			Data: data,
+			Pagination: Pagination{
+				Limit:   p.limit,
+				Offset:  p.offset,
+				Total:   total,
+				Count:   len(data),
+				HasMore: p.offset+len(data) < total,
+			},
+			Fetcher: fetcher,
+			Params:  p,
+			Ctx:     ctx,
+		}, nil
+	}
+	return fetcher
+}
+
+func TestPagination(t *testing.T) {
+	ctx := context.Background()
+	page, err := newTestFetcher(5, -1)(ctx, testParams{limit: 2})
+	if err != nil {
+		t.Fatalf("fetch error = %v", err)
+	}
+
+	var pages [][]int
+	for page != nil {
+		pages = append(pages, page.Data)
+		if page, err = page.GetNextPage(); err != nil {
+			t.Fatalf("GetNextPage() error = %v", err)
+		}
+	}
+	if len(pages) != 3 || pages[2][0] != 4 {
+		t.Errorf("pages = %v", pages)
+	}
+}
+
+func TestPaginationIterator(t *testing.T) {
+	ctx := context.Background()
+	iter := NewPageIterator(ctx, testParams{limit: 2}, newTestFetcher(5, 4))
+
+	items, err := iter.Collect(ctx, 0)
+	if err == nil {
+		t.Fatal("Collect() error = nil, want fetch error")
+	}
+	if len(items) != 4 {
+		t.Errorf("Collect() = %v, want 4 items before the error", items)
+	}
+}
+
+func TestAutoPagination(t *testing.T) {
+	ctx := context.Background()
+	iter := NewPageIterator(ctx, testParams{limit: 2}, newTestFetcher(5, -1))
+
+	items, err := iter.Collect(ctx, 3)
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if len(items) != 3 || items[2] != 2 {
+		t.Errorf("Collect(3) = %v", items)
+	}
+
+	rest, err := iter.Collect(ctx, 0)
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if len(rest) != 2 || rest[1] != 4 {
+		t.Errorf("Collect() = %v", rest)
+	}
+}
+
+func TestGetNextPageWithoutParams(t *testing.T) {
+	page := &Page[int]{
+		Data:       []int{0},
+		Pagination: Pagination{Limit: 1, Count: 1, Total: 2, HasMore: true},
+		Fetcher:    newTestFetcher(2, -1),
+	}
+	if next, err := page.GetNextPage(); !errors.Is(err, ErrNoParams) || next != nil {
+		t.Errorf("GetNextPage() = %v, %v, want ErrNoParams", next, err)
+	}
+}
@@ -4,8 +4,7 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/payOSHQ/payos-lib-golang/v2/internal/apierror"
-	"github.com/payOSHQ/payos-lib-golang/v2/internal/apijson"
+	"github.com/payOSHQ/payos-lib-golang/v2/apierror"
 )
 
 // ========================
@@ -186,7 +185,8 @@ func newPaymentRequests(client *Client) *PaymentRequests {
 }
 
 // Create creates a new payment link
-func (pr *PaymentRequests) Create(ctx context.Context, data CreatePaymentLinkRequest) (*CreatePaymentLinkResponse, error) {
+func (pr *PaymentRequests) Create(ctx context.Context, data CreatePaymentLinkRequest, opts ...RequestOption) (*CreatePaymentLinkResponse, error) {
+	opts = withOperation("PaymentRequests.Create", opts)
 	// Validate required fields
 	if data.OrderCode == 0 || data.Amount == 0 || data.Description == "" || data.CancelUrl == "" || data.ReturnUrl == "" {
 		return nil, apierror.NewPayOSError("OrderCode, Amount, ReturnUrl, CancelUrl, Description must not be undefined or null.")
@@ -197,25 +197,20 @@ func (pr *PaymentRequests) Create(ctx context.Context, data CreatePaymentLinkReq
 		return nil, apierror.NewPayOSError("order code out of range")
 	}
 
-	result, err := pr.client.Post(ctx, "/v2/payment-requests", data, &SignatureOpts{
-		Request:  "create-payment-link",
-		Response: "body",
-	}, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	// Convert result to CreatePaymentLinkResponse
-	var response CreatePaymentLinkResponse
-	if err := apijson.ConvertInterface(result, &response); err != nil {
-		return nil, apierror.NewPayOSError("failed to parse response")
-	}
-
-	return &response, nil
+	return Do[CreatePaymentLinkResponse](ctx, pr.client, &RequestOptions{
+		Method: "POST",
+		Path:   "/v2/payment-requests",
+		Body:   data,
+		SignatureOpts: &SignatureOpts{
+			Request:  "create-payment-link",
+			Response: "body",
+		},
+	}, opts...)
 }
 
 // Get retrieves payment link information by payment link ID or order code
-func (pr *PaymentRequests) Get(ctx context.Context, id interface{}) (*PaymentLink, error) {
+func (pr *PaymentRequests) Get(ctx context.Context, id interface{}, opts ...RequestOption) (*PaymentLink, error) {
+	opts = withOperation("PaymentRequests.Get", opts)
 	var idStr string
 	switch v := id.(type) {
 	case string:
@@ -233,21 +228,16 @@ func (pr *PaymentRequests) Get(ctx context.Context, id interface{}) (*PaymentLin
 	}
 
 	path := fmt.Sprintf("/v2/payment-requests/%s", idStr)
-	result, err := pr.client.Get(ctx, path, nil, nil, &SignatureOpts{Response: "body"})
-	if err != nil {
-		return nil, err
-	}
-
-	var response PaymentLink
-	if err := apijson.ConvertInterface(result, &response); err != nil {
-		return nil, apierror.NewPayOSError("failed to parse response")
-	}
-
-	return &response, nil
+	return Do[PaymentLink](ctx, pr.client, &RequestOptions{
+		Method:        "GET",
+		Path:          path,
+		SignatureOpts: &SignatureOpts{Response: "body"},
+	}, opts...)
 }
 
 // Cancel cancels a payment link by payment link ID or order code
-func (pr *PaymentRequests) Cancel(ctx context.Context, id interface{}, cancellationReason *string) (*PaymentLink, error) {
+func (pr *PaymentRequests) Cancel(ctx context.Context, id interface{}, cancellationReason *string, opts ...RequestOption) (*PaymentLink, error) {
+	opts = withOperation("PaymentRequests.Cancel", opts)
 	var idStr string
 	switch v := id.(type) {
 	case string:
@@ -272,15 +262,10 @@ func (pr *PaymentRequests) Cancel(ctx context.Context, id interface{}, cancellat
 		}
 	}
 
-	result, err := pr.client.Post(ctx, path, body, &SignatureOpts{Response: "body"}, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	var response PaymentLink
-	if err := apijson.ConvertInterface(result, &response); err != nil {
-		return nil, apierror.NewPayOSError("failed to parse response")
-	}
-
-	return &response, nil
+	return Do[PaymentLink](ctx, pr.client, &RequestOptions{
+		Method:        "POST",
+		Path:          path,
+		Body:          body,
+		SignatureOpts: &SignatureOpts{Response: "body"},
+	}, opts...)
 }
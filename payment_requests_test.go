@@ -1,23 +1,89 @@
 package payos
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/payOSHQ/payos-lib-golang/v2/apierror"
+)
 
 func TestPaymentRequests(t *testing.T) {
 	// TODO: implement test
 	t.Skip("Test implementation pending")
 }
 
+func testPaymentLinkRequest(orderCode int64) CreatePaymentLinkRequest {
+	return CreatePaymentLinkRequest{
+		OrderCode:   orderCode,
+		Amount:      2000,
+		Description: "payment",
+		ReturnUrl:   "https://example.com/return",
+		CancelUrl:   "https://example.com/cancel",
+	}
+}
+
 func TestCreatePaymentLink(t *testing.T) {
-	// TODO: implement test
-	t.Skip("Test implementation pending")
+	client, _ := newTestPayOS(t)
+	ctx := context.Background()
+
+	link, err := client.PaymentRequests.Create(ctx, testPaymentLinkRequest(123))
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if link.OrderCode != 123 || link.Amount != 2000 || link.Status != PaymentLinkStatusPending {
+		t.Errorf("Create() = %+v", link)
+	}
+
+	_, err = client.PaymentRequests.Create(ctx, testPaymentLinkRequest(123))
+	var apiErr *apierror.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Create() duplicate error = %v, want *apierror.APIError", err)
+	}
+	if !errors.Is(err, apierror.ErrOrderCodeExists) || apiErr.Retryable() {
+		t.Errorf("Create() duplicate error = %v, want non-retryable ErrOrderCodeExists", err)
+	}
 }
 
 func TestGetPaymentLinkInformation(t *testing.T) {
-	// TODO: implement test
-	t.Skip("Test implementation pending")
+	client, srv := newTestPayOS(t)
+	ctx := context.Background()
+
+	created, err := client.PaymentRequests.Create(ctx, testPaymentLinkRequest(456))
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := srv.MarkPaid(int64(456)); err != nil {
+		t.Fatalf("MarkPaid() error = %v", err)
+	}
+
+	link, err := client.PaymentRequests.Get(ctx, created.PaymentLinkId)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if link.Status != PaymentLinkStatusPaid || link.AmountPaid != 2000 || len(link.Transactions) != 1 {
+		t.Errorf("Get() = %+v", link)
+	}
 }
 
 func TestCancelPaymentLink(t *testing.T) {
-	// TODO: implement test
-	t.Skip("Test implementation pending")
+	client, _ := newTestPayOS(t)
+	ctx := context.Background()
+
+	if _, err := client.PaymentRequests.Create(ctx, testPaymentLinkRequest(789)); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	reason := "changed my mind"
+	link, err := client.PaymentRequests.Cancel(ctx, int64(789), &reason)
+	if err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+	if link.Status != PaymentLinkStatusCancelled || link.CancellationReason == nil || *link.CancellationReason != reason {
+		t.Errorf("Cancel() = %+v", link)
+	}
+
+	if _, err := client.PaymentRequests.Cancel(ctx, int64(789), nil); err == nil {
+		t.Error("Cancel() of a cancelled link error = nil")
+	}
 }
@@ -9,8 +9,8 @@ import (
 	"net/http"
 	"strings"
 
-	"github.com/payOSHQ/payos-lib-golang/v2/internal/apierror"
-	"github.com/payOSHQ/payos-lib-golang/v2/internal/crypto"
+	"github.com/payOSHQ/payos-lib-golang/v2/apierror"
+	"github.com/payOSHQ/payos-lib-golang/v2/signature"
 )
 
 // Deprecated: Use NewPayOS() constructor instead
@@ -27,7 +27,13 @@ var PayOSPartnerCode string
 
 // PayOS is the main client for interacting with PayOS API
 type PayOS struct {
-	Client          *Client
+	Client *Client
+
+	// PayoutClient is the client of Payouts and PayoutsAccount
+	// It signs with the payout channel when PayOSOptions.Payment or PayOSOptions.Payout is set
+	// and is Client otherwise
+	PayoutClient *Client
+
 	PaymentRequests *PaymentRequests
 	Webhooks        *Webhooks
 	Payouts         *Payouts
@@ -37,20 +43,28 @@ type PayOS struct {
 // NewPayOS creates a new PayOS client with the provided options
 // This is the recommended way to create a PayOS client
 func NewPayOS(opts *PayOSOptions) (*PayOS, error) {
-	client, err := NewClient(opts)
+	opts = NewPayOSOptions(opts)
+	payment, payout, err := opts.channelCredentials()
 	if err != nil {
 		return nil, err
 	}
 
+	client := newClient(opts, payment)
+	payoutClient := client
+	if opts.Payment != nil || opts.Payout != nil {
+		payoutClient = client.withCredentials(payout)
+	}
+
 	payos := &PayOS{
-		Client: client,
+		Client:       client,
+		PayoutClient: payoutClient,
 	}
 
 	// Initialize resources
 	payos.PaymentRequests = newPaymentRequests(client)
 	payos.Webhooks = &Webhooks{client: client}
-	payos.Payouts = newPayouts(client)
-	payos.PayoutsAccount = newPayoutsAccount(client)
+	payos.Payouts = newPayouts(payoutClient)
+	payos.PayoutsAccount = newPayoutsAccount(payoutClient)
 
 	return payos, nil
 }
@@ -134,7 +148,7 @@ func CreatePaymentLink(paymentData CheckoutRequestType) (*CheckoutResponseDataTy
 	}
 
 	url := fmt.Sprintf("%s/v2/payment-requests", PayOSBaseUrl)
-	signaturePaymentRequest, _ := crypto.CreateSignatureOfPaymentRequest(paymentData, PayOSChecksumKey)
+	signaturePaymentRequest, _ := signature.Sign(signature.SchemePaymentRequest, PayOSChecksumKey, paymentData)
 	paymentData.Signature = &signaturePaymentRequest
 	checkoutRequest, err := json.Marshal(paymentData)
 	if err != nil {
@@ -168,8 +182,7 @@ func CreatePaymentLink(paymentData CheckoutRequestType) (*CheckoutResponseDataTy
 	}
 
 	if paymentLinkRes.Code == "00" {
-		paymentLinkResSignature, _ := crypto.CreateSignatureFromObj(paymentLinkRes.Data, PayOSChecksumKey)
-		if paymentLinkResSignature != *paymentLinkRes.Signature {
+		if paymentLinkRes.Signature == nil || signature.Verify(signature.SchemeBody, PayOSChecksumKey, paymentLinkRes.Data, *paymentLinkRes.Signature) != nil {
 			return nil, apierror.NewPayOSError("data not integrity")
 		}
 		if paymentLinkRes.Data != nil {
@@ -232,8 +245,7 @@ func GetPaymentLinkInformation(orderCode string) (*PaymentLinkDataType, error) {
 	}
 
 	if paymentLinkInfoRes.Code == "00" {
-		paymentLinkInfoResSignature, _ := crypto.CreateSignatureFromObj(paymentLinkInfoRes.Data, PayOSChecksumKey)
-		if paymentLinkInfoResSignature != *paymentLinkInfoRes.Signature {
+		if paymentLinkInfoRes.Signature == nil || signature.Verify(signature.SchemeBody, PayOSChecksumKey, paymentLinkInfoRes.Data, *paymentLinkInfoRes.Signature) != nil {
 			return nil, apierror.NewPayOSError("data not integrity")
 		}
 
@@ -305,8 +317,7 @@ func CancelPaymentLink(orderCode string, cancellationReason *string) (*PaymentLi
 	}
 
 	if cancelPaymentLinkRes.Code == "00" {
-		paymentLinkResSignature, _ := crypto.CreateSignatureFromObj(cancelPaymentLinkRes.Data, PayOSChecksumKey)
-		if paymentLinkResSignature != *cancelPaymentLinkRes.Signature {
+		if cancelPaymentLinkRes.Signature == nil || signature.Verify(signature.SchemeBody, PayOSChecksumKey, cancelPaymentLinkRes.Data, *cancelPaymentLinkRes.Signature) != nil {
 			return nil, apierror.NewPayOSError("data not integrity")
 		}
 		if cancelPaymentLinkRes.Data != nil {
@@ -399,12 +410,7 @@ func VerifyPaymentWebhookData(webhookBody WebhookType) (*WebhookDataType, error)
 		return nil, apierror.NewPayOSError("signature invalid")
 	}
 
-	signData, err := crypto.CreateSignatureFromObj(webhookBody.Data, PayOSChecksumKey)
-	if err != nil {
-		return nil, apierror.NewPayOSError("internal server error")
-	}
-
-	if signData != webhookBody.Signature {
+	if err := signature.Verify(signature.SchemeBody, PayOSChecksumKey, webhookBody.Data, webhookBody.Signature); err != nil {
 		return nil, apierror.NewPayOSError("data not integrity")
 	}
 
@@ -1,8 +1,160 @@
 package payos
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/payOSHQ/payos-lib-golang/v2/apierror"
+	"github.com/payOSHQ/payos-lib-golang/v2/payostest"
+)
+
+// newTestPayOS starts a fake payOS server and returns a client configured against it
+func newTestPayOS(t *testing.T) (*PayOS, *payostest.Server) {
+	t.Helper()
+
+	srv := payostest.NewServer(nil)
+	t.Cleanup(srv.Close)
+
+	client, err := NewPayOS(&PayOSOptions{
+		ClientId:    srv.ClientId,
+		ApiKey:      srv.ApiKey,
+		ChecksumKey: srv.ChecksumKey,
+		BaseURL:     srv.URL,
+	})
+	if err != nil {
+		t.Fatalf("NewPayOS() error = %v", err)
+	}
+	return client, srv
+}
 
 func TestClient(t *testing.T) {
 	// TODO: implement test
 	t.Skip("Test implementation pending")
 }
+
+// newChannelServer starts a fake payOS server with separate payout channel credentials
+func newChannelServer(t *testing.T) *payostest.Server {
+	t.Helper()
+
+	srv := payostest.NewServer(&payostest.Options{
+		PayoutClientId:    "payout-client-id",
+		PayoutApiKey:      "payout-api-key",
+		PayoutChecksumKey: "payout-checksum-key",
+	})
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestPayOSChannels(t *testing.T) {
+	srv := newChannelServer(t)
+	client, err := NewPayOS(&PayOSOptions{
+		ClientId:    srv.ClientId,
+		ApiKey:      srv.ApiKey,
+		ChecksumKey: srv.ChecksumKey,
+		Payout: &ChannelOptions{
+			ClientId:    srv.PayoutClientId,
+			ApiKey:      srv.PayoutApiKey,
+			ChecksumKey: srv.PayoutChecksumKey,
+		},
+		BaseURL: srv.URL,
+	})
+	if err != nil {
+		t.Fatalf("NewPayOS() error = %v", err)
+	}
+
+	ctx := context.Background()
+	if _, err := client.PaymentRequests.Create(ctx, testPaymentLinkRequest(901)); err != nil {
+		t.Errorf("PaymentRequests.Create() error = %v", err)
+	}
+	if _, err := client.Payouts.Create(ctx, testPayoutRequest("channel-payout"), nil); err != nil {
+		t.Errorf("Payouts.Create() error = %v", err)
+	}
+	if _, err := client.PayoutsAccount.Balance(ctx); err != nil {
+		t.Errorf("PayoutsAccount.Balance() error = %v", err)
+	}
+	if client.PayoutClient == client.Client {
+		t.Error("PayoutClient is Client with a payout channel")
+	}
+}
+
+func TestPayOSPayoutChannelFromEnv(t *testing.T) {
+	srv := newChannelServer(t)
+	t.Setenv("PAYOS_CLIENT_ID", srv.ClientId)
+	t.Setenv("PAYOS_API_KEY", srv.ApiKey)
+	t.Setenv("PAYOS_CHECKSUM_KEY", srv.ChecksumKey)
+	t.Setenv("PAYOS_PAYOUT_CLIENT_ID", srv.PayoutClientId)
+	t.Setenv("PAYOS_PAYOUT_API_KEY", srv.PayoutApiKey)
+	t.Setenv("PAYOS_PAYOUT_CHECKSUM_KEY", srv.PayoutChecksumKey)
+	ctx := context.Background()
+
+	client, err := NewPayOS(&PayOSOptions{BaseURL: srv.URL})
+	if err != nil {
+		t.Fatalf("NewPayOS() error = %v", err)
+	}
+	if _, err := client.PaymentRequests.Create(ctx, testPaymentLinkRequest(903)); err != nil {
+		t.Errorf("PaymentRequests.Create() error = %v", err)
+	}
+	if _, err := client.PayoutsAccount.Balance(ctx); err != nil {
+		t.Errorf("PayoutsAccount.Balance() error = %v", err)
+	}
+
+	// Credentials passed in code are never replaced by the payout environment
+	client, err = NewPayOS(&PayOSOptions{ClientId: "id", ApiKey: "key", ChecksumKey: "checksum-key"})
+	if err != nil {
+		t.Fatalf("NewPayOS() with credentials in code error = %v", err)
+	}
+	if client.PayoutClient != client.Client {
+		t.Error("PayoutClient uses the payout environment over credentials in code")
+	}
+
+	// A partial payout environment is not configured, unless Payout asks for it
+	t.Setenv("PAYOS_PAYOUT_API_KEY", "")
+	client, err = NewPayOS(nil)
+	if err != nil {
+		t.Fatalf("NewPayOS() with a partial payout environment error = %v", err)
+	}
+	if client.PayoutClient != client.Client {
+		t.Error("PayoutClient uses a partial payout environment")
+	}
+	_, err = NewPayOS(&PayOSOptions{Payout: &ChannelOptions{}})
+	if err == nil || !strings.Contains(err.Error(), "PAYOS_PAYOUT_API_KEY") {
+		t.Errorf("NewPayOS() error = %v, want the missing PAYOS_PAYOUT_API_KEY", err)
+	}
+}
+
+func TestPayOSMissingChannel(t *testing.T) {
+	srv := newChannelServer(t)
+	client, err := NewPayOS(&PayOSOptions{
+		Payment: &ChannelOptions{
+			ClientId:    srv.ClientId,
+			ApiKey:      srv.ApiKey,
+			ChecksumKey: srv.ChecksumKey,
+		},
+		BaseURL: srv.URL,
+	})
+	if err != nil {
+		t.Fatalf("NewPayOS() error = %v", err)
+	}
+
+	ctx := context.Background()
+	if _, err := client.PaymentRequests.Create(ctx, testPaymentLinkRequest(902)); err != nil {
+		t.Errorf("PaymentRequests.Create() error = %v", err)
+	}
+	_, err = client.Payouts.Create(ctx, testPayoutRequest("missing-channel"), nil)
+	if !errors.Is(err, apierror.ErrCredentials) || !strings.Contains(err.Error(), "PAYOS_PAYOUT_CLIENT_ID") {
+		t.Errorf("Payouts.Create() error = %v, want ErrCredentials naming PAYOS_PAYOUT_CLIENT_ID", err)
+	}
+
+	client, err = NewPayOS(&PayOSOptions{
+		Payout:  &ChannelOptions{ClientId: srv.PayoutClientId, ApiKey: srv.PayoutApiKey, ChecksumKey: srv.PayoutChecksumKey},
+		BaseURL: srv.URL,
+	})
+	if err != nil {
+		t.Fatalf("NewPayOS() of a payout only instance error = %v", err)
+	}
+	if _, err := client.PaymentRequests.Get(ctx, "902"); !errors.Is(err, apierror.ErrCredentials) {
+		t.Errorf("PaymentRequests.Get() error = %v, want ErrCredentials", err)
+	}
+}
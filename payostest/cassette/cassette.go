@@ -0,0 +1,435 @@
+// Package cassette records payOS request and response pairs to files and replays them offline.
+//
+// A Cassette is a payos.Middleware. In record mode requests reach the real API
+// and every interaction is kept, with credentials and signatures scrubbed. In
+// replay mode requests are answered from the cassette, matched on method, path,
+// query and canonical JSON body, and response signatures are regenerated with a
+// test checksum key so the client verifies them as usual:
+//
+//	c, err := cassette.Open("testdata/payouts.json", &cassette.Options{ChecksumKey: "test-checksum-key"})
+//	if err != nil {
+//	    t.Fatal(err)
+//	}
+//	defer c.Save()
+//
+//	client, _ := payos.NewPayOS(&payos.PayOSOptions{
+//	    ClientId:    os.Getenv("PAYOS_CLIENT_ID"),
+//	    ApiKey:      os.Getenv("PAYOS_API_KEY"),
+//	    ChecksumKey: "test-checksum-key",
+//	    Middlewares: []payos.Middleware{c.Middleware()},
+//	})
+//
+// When recording, the client needs the real checksum key and Options.ChecksumKey must be empty.
+package cassette
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/payOSHQ/payos-lib-golang/v2"
+	"github.com/payOSHQ/payos-lib-golang/v2/signature"
+)
+
+// Mode selects whether a cassette records or replays
+type Mode int
+
+const (
+	// ModeAuto replays when the cassette file exists and records otherwise
+	ModeAuto Mode = iota
+	// ModeReplay answers every request from the cassette and never reaches the network
+	ModeReplay
+	// ModeRecord sends every request and replaces the cassette on Save
+	ModeRecord
+)
+
+// Scrubbed replaces credentials and signatures in recorded interactions
+const Scrubbed = "[SCRUBBED]"
+
+// ErrNoInteraction is returned in replay mode for a request missing from the cassette
+var ErrNoInteraction = errors.New("cassette: no recorded interaction")
+
+// DefaultScrubHeaders are the request and response headers scrubbed when recording
+var DefaultScrubHeaders = []string{"x-client-id", "x-api-key", "x-signature", "authorization", "cookie", "set-cookie"}
+
+// ignoredResponseHeaders change on every request and are not recorded
+var ignoredResponseHeaders = []string{"Date", "Content-Length"}
+
+// Options configures a Cassette
+type Options struct {
+	// Mode defaults to ModeAuto
+	Mode Mode
+
+	// ChecksumKey signs replayed responses, so it must match the checksum key of the client
+	// When empty, responses are replayed with their scrubbed signatures
+	ChecksumKey string
+
+	// ScrubHeaders lists the headers scrubbed when recording
+	// Defaults to DefaultScrubHeaders
+	ScrubHeaders []string
+}
+
+// Interaction is a recorded request and response pair
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded request
+type Request struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Query   string            `json:"query,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+// Response is a recorded response
+// JSON bodies are kept as is, other bodies are base64 encoded
+type Response struct {
+	StatusCode int               `json:"statusCode"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       json.RawMessage   `json:"body,omitempty"`
+	BodyBase64 string            `json:"bodyBase64,omitempty"`
+}
+
+// file is the format of a cassette file
+type file struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Cassette records and replays the interactions stored in a file
+type Cassette struct {
+	path         string
+	mode         Mode
+	checksumKey  string
+	scrubHeaders map[string]bool
+
+	mu           sync.Mutex
+	interactions []*Interaction
+	replayed     map[*Interaction]bool
+}
+
+// Open loads the cassette at path, or starts an empty one when recording
+func Open(path string, opts *Options) (*Cassette, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	c := &Cassette{
+		path:         path,
+		mode:         opts.Mode,
+		checksumKey:  opts.ChecksumKey,
+		scrubHeaders: make(map[string]bool),
+		replayed:     make(map[*Interaction]bool),
+	}
+	scrubHeaders := opts.ScrubHeaders
+	if scrubHeaders == nil {
+		scrubHeaders = DefaultScrubHeaders
+	}
+	for _, h := range scrubHeaders {
+		c.scrubHeaders[http.CanonicalHeaderKey(h)] = true
+	}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist) && c.mode != ModeReplay:
+		c.mode = ModeRecord
+		return c, nil
+	case err != nil:
+		return nil, fmt.Errorf("cassette: %w", err)
+	case c.mode == ModeRecord:
+		return c, nil
+	}
+
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("cassette: invalid cassette %s: %w", path, err)
+	}
+	c.interactions = f.Interactions
+	c.mode = ModeReplay
+	return c, nil
+}
+
+// Recording reports whether requests are sent and recorded
+func (c *Cassette) Recording() bool {
+	return c.mode == ModeRecord
+}
+
+// Interactions returns the interactions of the cassette
+func (c *Cassette) Interactions() []*Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*Interaction(nil), c.interactions...)
+}
+
+// Middleware returns the cassette as a payos.Middleware
+// Add it last so it records the requests as sent by the other middlewares
+func (c *Cassette) Middleware() payos.Middleware {
+	return func(next payos.RequestHandler) payos.RequestHandler {
+		return func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			if c.mode == ModeRecord {
+				return c.record(ctx, next, req)
+			}
+			return c.replay(req)
+		}
+	}
+}
+
+// Save writes the recorded interactions to the cassette file
+// It does nothing in replay mode
+func (c *Cassette) Save() error {
+	if c.mode != ModeRecord {
+		return nil
+	}
+	c.mu.Lock()
+	data, err := json.MarshalIndent(file{Interactions: c.interactions}, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("cassette: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return fmt.Errorf("cassette: %w", err)
+	}
+	if err := os.WriteFile(c.path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("cassette: %w", err)
+	}
+	return nil
+}
+
+// record sends the request and keeps the scrubbed interaction
+func (c *Cassette) record(ctx context.Context, next payos.RequestHandler, req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := next(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	interaction := &Interaction{
+		Request: Request{
+			Method:  req.Method,
+			Path:    req.URL.Path,
+			Query:   req.URL.RawQuery,
+			Headers: c.scrubbedHeaders(req.Header, nil),
+			Body:    scrubBody(reqBody),
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Headers:    c.scrubbedHeaders(resp.Header, ignoredResponseHeaders),
+		},
+	}
+	if json.Valid(respBody) {
+		interaction.Response.Body = scrubBody(respBody)
+	} else if len(respBody) > 0 {
+		interaction.Response.BodyBase64 = base64.StdEncoding.EncodeToString(respBody)
+	}
+
+	c.mu.Lock()
+	c.interactions = append(c.interactions, interaction)
+	c.mu.Unlock()
+	return resp, nil
+}
+
+// replay answers the request with the first unused matching interaction
+// Once every match was used, the last one is replayed again
+func (c *Cassette) replay(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	key := matchKey(req.Method, req.URL.Path, req.URL.RawQuery, reqBody)
+
+	c.mu.Lock()
+	var match *Interaction
+	for _, interaction := range c.interactions {
+		r := interaction.Request
+		if matchKey(r.Method, r.Path, r.Query, r.Body) != key {
+			continue
+		}
+		match = interaction
+		if !c.replayed[interaction] {
+			break
+		}
+	}
+	if match != nil {
+		c.replayed[match] = true
+	}
+	c.mu.Unlock()
+
+	if match == nil {
+		return nil, fmt.Errorf("%w for %s %s", ErrNoInteraction, req.Method, req.URL.RequestURI())
+	}
+	return c.response(req, match.Response)
+}
+
+// response builds the replayed response, signing it with the test checksum key
+func (c *Cassette) response(req *http.Request, recorded Response) (*http.Response, error) {
+	header := http.Header{}
+	for key, value := range recorded.Headers {
+		header.Set(key, value)
+	}
+
+	body := []byte(recorded.Body)
+	if recorded.BodyBase64 != "" {
+		var err error
+		if body, err = base64.StdEncoding.DecodeString(recorded.BodyBase64); err != nil {
+			return nil, fmt.Errorf("cassette: invalid response body: %w", err)
+		}
+	}
+	if c.checksumKey != "" && len(recorded.Body) > 0 {
+		var err error
+		if body, err = c.sign(header, body); err != nil {
+			return nil, err
+		}
+	}
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// sign regenerates the scrubbed body and header signatures of a JSON response
+func (c *Cassette) sign(header http.Header, body []byte) ([]byte, error) {
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return body, nil
+	}
+	data, ok := envelope["data"]
+	if !ok || string(data) == "null" {
+		return body, nil
+	}
+
+	if header.Get("x-signature") != "" {
+		sig, err := signature.Sign(signature.SchemeHeader, c.checksumKey, data)
+		if err != nil {
+			return nil, fmt.Errorf("cassette: failed to sign response: %w", err)
+		}
+		header.Set("x-signature", sig)
+	}
+	if sigField, ok := envelope["signature"]; ok && string(sigField) != "null" {
+		sig, err := signature.Sign(signature.SchemeBody, c.checksumKey, data)
+		if err != nil {
+			return nil, fmt.Errorf("cassette: failed to sign response: %w", err)
+		}
+		envelope["signature"], _ = json.Marshal(sig)
+		return json.Marshal(envelope)
+	}
+	return body, nil
+}
+
+// scrubbedHeaders flattens headers, replacing credentials and signatures
+func (c *Cassette) scrubbedHeaders(header http.Header, ignore []string) map[string]string {
+	headers := make(map[string]string, len(header))
+	for key, values := range header {
+		key = http.CanonicalHeaderKey(key)
+		if containsHeader(ignore, key) {
+			continue
+		}
+		if c.scrubHeaders[key] {
+			headers[key] = Scrubbed
+			continue
+		}
+		headers[key] = strings.Join(values, ", ")
+	}
+	return headers
+}
+
+// readRequestBody reads the request body and restores it for the next handler
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("cassette: failed to read request body: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// scrubBody replaces the top-level signature field of a JSON body
+func scrubBody(body []byte) json.RawMessage {
+	if len(body) == 0 {
+		return nil
+	}
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(body, &object); err != nil {
+		return body
+	}
+	if sig, ok := object["signature"]; ok && string(sig) != "null" {
+		object["signature"], _ = json.Marshal(Scrubbed)
+		if scrubbed, err := json.Marshal(object); err == nil {
+			return scrubbed
+		}
+	}
+	return body
+}
+
+// matchKey identifies a request by method, path, sorted query and canonical JSON body
+// Signatures are left out since they depend on the checksum key
+func matchKey(method, path, rawQuery string, body []byte) string {
+	query, err := url.ParseQuery(rawQuery)
+	if err == nil {
+		rawQuery = query.Encode()
+	}
+	return method + " " + path + "?" + rawQuery + " " + canonicalBody(body)
+}
+
+// canonicalBody re-encodes a JSON body with sorted keys and without its signature
+func canonicalBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return string(body)
+	}
+	if object, ok := value.(map[string]interface{}); ok {
+		delete(object, "signature")
+	}
+	canonical, err := json.Marshal(value)
+	if err != nil {
+		return string(body)
+	}
+	return string(canonical)
+}
+
+func containsHeader(headers []string, key string) bool {
+	for _, h := range headers {
+		if http.CanonicalHeaderKey(h) == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,229 @@
+package cassette_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/payOSHQ/payos-lib-golang/v2"
+	"github.com/payOSHQ/payos-lib-golang/v2/apierror"
+	"github.com/payOSHQ/payos-lib-golang/v2/payostest"
+	"github.com/payOSHQ/payos-lib-golang/v2/payostest/cassette"
+)
+
+const testChecksumKey = "cassette-checksum-key"
+
+// flowResult collects what the flows returned, to compare recording and replay
+type flowResult struct {
+	paymentLinkId string
+	status        payos.PaymentLinkStatus
+	payoutId      string
+	invoiceId     string
+	pdf           []byte
+}
+
+// runFlows runs the payment request, payout and invoice download flows
+// markPaid is nil on replay, where the server is gone
+func runFlows(t *testing.T, client *payos.PayOS, markPaid func(id interface{}) error) flowResult {
+	t.Helper()
+	ctx := context.Background()
+	var res flowResult
+
+	link, err := client.PaymentRequests.Create(ctx, payos.CreatePaymentLinkRequest{
+		OrderCode:   901,
+		Amount:      2000,
+		Description: "cassette",
+		ReturnUrl:   "https://example.com/return",
+		CancelUrl:   "https://example.com/cancel",
+	})
+	if err != nil {
+		t.Fatalf("PaymentRequests.Create() error = %v", err)
+	}
+	res.paymentLinkId = link.PaymentLinkId
+
+	if markPaid != nil {
+		if err := markPaid(901); err != nil {
+			t.Fatalf("MarkPaid() error = %v", err)
+		}
+	}
+	info, err := client.PaymentRequests.Get(ctx, 901)
+	if err != nil {
+		t.Fatalf("PaymentRequests.Get() error = %v", err)
+	}
+	res.status = info.Status
+
+	payout, err := client.Payouts.Create(ctx, payos.PayoutRequest{
+		ReferenceId:     "cassette-1",
+		Amount:          50000,
+		Description:     "payout",
+		ToBin:           "970422",
+		ToAccountNumber: "0123456789",
+	}, nil)
+	if err != nil {
+		t.Fatalf("Payouts.Create() error = %v", err)
+	}
+	if _, err := client.Payouts.Get(ctx, payout.Id); err != nil {
+		t.Fatalf("Payouts.Get() error = %v", err)
+	}
+	res.payoutId = payout.Id
+
+	invoices, err := client.PaymentRequests.Invoices.Get(ctx, 901)
+	if err != nil {
+		t.Fatalf("Invoices.Get() error = %v", err)
+	}
+	if len(invoices.Invoices) == 0 {
+		t.Fatal("Invoices.Get() returned no invoices")
+	}
+	res.invoiceId = invoices.Invoices[0].InvoiceId
+	file, err := client.PaymentRequests.Invoices.Download(ctx, res.invoiceId, 901)
+	if err != nil {
+		t.Fatalf("Invoices.Download() error = %v", err)
+	}
+	res.pdf = file.Data
+	return res
+}
+
+func newClient(t *testing.T, opts *payos.PayOSOptions, c *cassette.Cassette) *payos.PayOS {
+	t.Helper()
+	opts.Middlewares = []payos.Middleware{c.Middleware()}
+	client, err := payos.NewPayOS(opts)
+	if err != nil {
+		t.Fatalf("NewPayOS() error = %v", err)
+	}
+	return client
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "testdata", "flows.json")
+
+	srv := payostest.NewServer(&payostest.Options{PayoutBalance: 100000})
+	rec, err := cassette.Open(path, nil)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if !rec.Recording() {
+		t.Fatal("Recording() = false for a missing cassette")
+	}
+	client := newClient(t, &payos.PayOSOptions{
+		ClientId:    srv.ClientId,
+		ApiKey:      srv.ApiKey,
+		ChecksumKey: srv.ChecksumKey,
+		BaseURL:     srv.URL,
+	}, rec)
+	recorded := runFlows(t, client, srv.MarkPaid)
+	if err := rec.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	srv.Close()
+
+	fixture, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	for _, secret := range []string{srv.ApiKey, srv.ClientId, srv.ChecksumKey} {
+		if bytes.Contains(fixture, []byte(secret)) {
+			t.Errorf("cassette contains secret %q", secret)
+		}
+	}
+
+	replay, err := cassette.Open(path, &cassette.Options{ChecksumKey: testChecksumKey})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if replay.Recording() {
+		t.Fatal("Recording() = true for an existing cassette")
+	}
+	client = newClient(t, &payos.PayOSOptions{
+		ClientId:    "client-id",
+		ApiKey:      "api-key",
+		ChecksumKey: testChecksumKey,
+		BaseURL:     srv.URL,
+	}, replay)
+	replayed := runFlows(t, client, nil)
+
+	if replayed.paymentLinkId != recorded.paymentLinkId || replayed.payoutId != recorded.payoutId || replayed.invoiceId != recorded.invoiceId {
+		t.Errorf("replayed = %+v, recorded = %+v", replayed, recorded)
+	}
+	if replayed.status != payos.PaymentLinkStatusPaid {
+		t.Errorf("replayed status = %s, want PAID", replayed.status)
+	}
+	if want := payostest.InvoicePDF(recorded.invoiceId); !bytes.Equal(replayed.pdf, want) {
+		t.Error("replayed invoice PDF differs from the recorded one")
+	}
+}
+
+func TestReplayRejectsSignaturesWithoutTestKey(t *testing.T) {
+	path := recordPaymentLink(t)
+
+	replay, err := cassette.Open(path, &cassette.Options{ChecksumKey: "other-key"})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	client := newClient(t, &payos.PayOSOptions{ClientId: "id", ApiKey: "key", ChecksumKey: testChecksumKey}, replay)
+
+	_, err = client.PaymentRequests.Get(context.Background(), 902)
+	if !errors.Is(err, apierror.ErrInvalidSignature) {
+		t.Errorf("Get() error = %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestReplayMissingInteraction(t *testing.T) {
+	path := recordPaymentLink(t)
+
+	replay, err := cassette.Open(path, &cassette.Options{Mode: cassette.ModeReplay, ChecksumKey: testChecksumKey})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	client := newClient(t, &payos.PayOSOptions{ClientId: "id", ApiKey: "key", ChecksumKey: testChecksumKey}, replay)
+
+	_, err = client.PaymentRequests.Get(context.Background(), 903, payos.WithMaxRetries(0))
+	if !errors.Is(err, cassette.ErrNoInteraction) {
+		t.Errorf("Get() error = %v, want ErrNoInteraction", err)
+	}
+}
+
+func TestReplayModeRequiresCassette(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.json")
+	if _, err := cassette.Open(path, &cassette.Options{Mode: cassette.ModeReplay}); err == nil {
+		t.Error("Open() error = nil for a missing cassette in replay mode")
+	}
+}
+
+// recordPaymentLink records the creation and lookup of payment link 902
+func recordPaymentLink(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "payment.json")
+
+	srv := payostest.NewServer(nil)
+	defer srv.Close()
+	rec, err := cassette.Open(path, nil)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	client := newClient(t, &payos.PayOSOptions{
+		ClientId:    srv.ClientId,
+		ApiKey:      srv.ApiKey,
+		ChecksumKey: srv.ChecksumKey,
+		BaseURL:     srv.URL,
+	}, rec)
+	ctx := context.Background()
+	if _, err := client.PaymentRequests.Create(ctx, payos.CreatePaymentLinkRequest{
+		OrderCode:   902,
+		Amount:      2000,
+		Description: "cassette",
+		ReturnUrl:   "https://example.com/return",
+		CancelUrl:   "https://example.com/cancel",
+	}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := client.PaymentRequests.Get(ctx, 902); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	return path
+}
@@ -0,0 +1,344 @@
+// Package chaos injects faults into payOS requests to exercise retry, timeout and signature handling.
+//
+// An Injector is a payos.Middleware driven by rules and a seeded random source,
+// so a failing run can be reproduced with the same seed:
+//
+//	injector := chaos.New(chaos.Options{
+//	    Seed: 42,
+//	    Rules: []chaos.Rule{
+//	        {Fault: chaos.FaultStatus, Path: "/v1/payouts", Probability: chaos.Chance(0.3), StatusCode: 503},
+//	        {Fault: chaos.FaultStatus, StatusCode: 429, RetryAfter: time.Second, Times: 1},
+//	        {Fault: chaos.FaultLatency, Path: "/v2/payment-requests/*", Latency: 2 * time.Second},
+//	    },
+//	})
+//
+//	client, _ := payos.NewPayOS(&payos.PayOSOptions{
+//	    // ...
+//	    Middlewares: []payos.Middleware{injector.Middleware()},
+//	})
+package chaos
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/payOSHQ/payos-lib-golang/v2"
+	"github.com/payOSHQ/payos-lib-golang/v2/apierror"
+)
+
+// Fault is a kind of injected failure
+type Fault int
+
+const (
+	// FaultLatency delays the request by Rule.Latency, or until the context is done
+	FaultLatency Fault = iota
+	// FaultReset sends the request, then drops the response with a connection reset error
+	FaultReset
+	// FaultTruncate cuts the response body in half and fails the read with io.ErrUnexpectedEOF
+	FaultTruncate
+	// FaultStatus answers with Rule.StatusCode without sending the request
+	FaultStatus
+	// FaultCorruptSignature corrupts the x-signature header of the response
+	FaultCorruptSignature
+	// FaultInvalidBodySignature corrupts the signature field of the response body
+	FaultInvalidBodySignature
+)
+
+func (f Fault) String() string {
+	switch f {
+	case FaultLatency:
+		return "latency"
+	case FaultReset:
+		return "reset"
+	case FaultTruncate:
+		return "truncate"
+	case FaultStatus:
+		return "status"
+	case FaultCorruptSignature:
+		return "corrupt_signature"
+	case FaultInvalidBodySignature:
+		return "invalid_body_signature"
+	default:
+		return "unknown"
+	}
+}
+
+// Rule injects a fault into the matching requests
+type Rule struct {
+	Fault Fault
+
+	// Method matches the request method, any method when empty
+	Method string
+
+	// Path matches the request path with path.Match syntax, such as /v1/payouts/*
+	// Any path when empty
+	Path string
+
+	// Probability is the chance in [0, 1] that a matching request gets the fault, see Chance
+	// Every matching request gets the fault when nil, and none when zero
+	Probability *float64
+
+	// Times caps the number of injections, unlimited when zero
+	Times int
+
+	// Latency is the delay of FaultLatency
+	Latency time.Duration
+
+	// StatusCode is the status of FaultStatus
+	// Defaults to 503
+	StatusCode int
+
+	// RetryAfter sets the Retry-After header of FaultStatus, rounded up to seconds
+	RetryAfter time.Duration
+}
+
+// Options configures an Injector
+type Options struct {
+	// Seed seeds the random source deciding which requests get a fault
+	Seed int64
+
+	// Rules are evaluated in order for every request, several faults may be combined
+	Rules []Rule
+}
+
+// Injector injects the faults of its rules into requests
+type Injector struct {
+	rules []Rule
+
+	mu       sync.Mutex
+	rng      *rand.Rand
+	fired    []int
+	injected map[Fault]int
+}
+
+// Chance returns a Rule.Probability of p
+func Chance(p float64) *float64 {
+	return &p
+}
+
+// New returns an Injector to add to PayOSOptions.Middlewares
+func New(opts Options) *Injector {
+	rules := append([]Rule(nil), opts.Rules...)
+	for i := range rules {
+		if rules[i].StatusCode == 0 {
+			rules[i].StatusCode = http.StatusServiceUnavailable
+		}
+	}
+	return &Injector{
+		rules:    rules,
+		rng:      rand.New(rand.NewSource(opts.Seed)),
+		fired:    make([]int, len(rules)),
+		injected: make(map[Fault]int),
+	}
+}
+
+// Injected returns the number of requests that got the fault
+func (i *Injector) Injected(fault Fault) int {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.injected[fault]
+}
+
+// Middleware returns the injector as a payos.Middleware
+// Add it last so faults look like they come from the network
+func (i *Injector) Middleware() payos.Middleware {
+	return func(next payos.RequestHandler) payos.RequestHandler {
+		return func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			faults := i.faults(req)
+			if len(faults) == 0 {
+				return next(ctx, req)
+			}
+
+			for _, rule := range faults {
+				if rule.Fault == FaultLatency {
+					if err := sleep(ctx, rule.Latency); err != nil {
+						return nil, err
+					}
+				}
+			}
+			for _, rule := range faults {
+				if rule.Fault == FaultStatus {
+					return statusResponse(req, rule), nil
+				}
+			}
+
+			resp, err := next(ctx, req)
+			if err != nil {
+				return resp, err
+			}
+			for _, rule := range faults {
+				switch rule.Fault {
+				case FaultReset:
+					resp.Body.Close()
+					return nil, resetError()
+				case FaultTruncate:
+					err = truncate(resp)
+				case FaultCorruptSignature:
+					if sig := resp.Header.Get("x-signature"); sig != "" {
+						resp.Header.Set("x-signature", corrupt(sig))
+					}
+				case FaultInvalidBodySignature:
+					err = corruptBodySignature(resp)
+				}
+				if err != nil {
+					resp.Body.Close()
+					return nil, err
+				}
+			}
+			return resp, nil
+		}
+	}
+}
+
+// faults returns the rules firing for a request
+func (i *Injector) faults(req *http.Request) []Rule {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	var faults []Rule
+	for n, rule := range i.rules {
+		if !rule.matches(req) {
+			continue
+		}
+		// Draw for every matching rule so a seed yields the same sequence whatever the outcome
+		draw := i.rng.Float64()
+		if draw >= rule.probability() || (rule.Times > 0 && i.fired[n] >= rule.Times) {
+			continue
+		}
+		i.fired[n]++
+		i.injected[rule.Fault]++
+		faults = append(faults, rule)
+	}
+	return faults
+}
+
+// probability returns the chance that a matching request gets the fault
+func (r Rule) probability() float64 {
+	if r.Probability == nil {
+		return 1
+	}
+	return *r.Probability
+}
+
+func (r Rule) matches(req *http.Request) bool {
+	if r.Method != "" && r.Method != req.Method {
+		return false
+	}
+	if r.Path == "" {
+		return true
+	}
+	ok, err := path.Match(r.Path, req.URL.Path)
+	return err == nil && ok
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// statusResponse builds the error response of FaultStatus
+func statusResponse(req *http.Request, rule Rule) *http.Response {
+	code := apierror.CodeInternalError
+	if rule.StatusCode == http.StatusTooManyRequests {
+		code = apierror.CodeTooManyRequests
+	}
+	body, _ := json.Marshal(map[string]interface{}{
+		"code": code,
+		"desc": http.StatusText(rule.StatusCode),
+		"data": nil,
+	})
+
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	if rule.RetryAfter > 0 {
+		header.Set("Retry-After", strconv.Itoa(int(math.Ceil(rule.RetryAfter.Seconds()))))
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rule.StatusCode, http.StatusText(rule.StatusCode)),
+		StatusCode:    rule.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// resetError looks like a connection reset by payOS
+func resetError() error {
+	return &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+}
+
+// truncate replaces the response body with its first half followed by io.ErrUnexpectedEOF
+func truncate(resp *http.Response) error {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+	resp.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body[:len(body)/2]), errReader{io.ErrUnexpectedEOF}))
+	return nil
+}
+
+// corruptBodySignature corrupts the signature field of a JSON response body
+func corruptBodySignature(resp *http.Response) error {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+
+	var envelope map[string]json.RawMessage
+	var sig string
+	if json.Unmarshal(body, &envelope) == nil && json.Unmarshal(envelope["signature"], &sig) == nil && sig != "" {
+		envelope["signature"], _ = json.Marshal(corrupt(sig))
+		if corrupted, err := json.Marshal(envelope); err == nil {
+			body = corrupted
+		}
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	resp.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	return nil
+}
+
+// corrupt changes the last character of a hex signature
+func corrupt(sig string) string {
+	last := byte('0')
+	if sig[len(sig)-1] == '0' {
+		last = '1'
+	}
+	return sig[:len(sig)-1] + string(last)
+}
+
+type errReader struct {
+	err error
+}
+
+func (r errReader) Read(p []byte) (int, error) {
+	return 0, r.err
+}
@@ -0,0 +1,263 @@
+package chaos_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/payOSHQ/payos-lib-golang/v2"
+	"github.com/payOSHQ/payos-lib-golang/v2/apierror"
+	"github.com/payOSHQ/payos-lib-golang/v2/payostest"
+	"github.com/payOSHQ/payos-lib-golang/v2/payostest/chaos"
+)
+
+// newTestPayOS returns a client of a fake payOS server with the injector as its last middleware
+func newTestPayOS(t *testing.T, injector *chaos.Injector, retryAfter *time.Duration) (*payos.PayOS, *payostest.Server) {
+	t.Helper()
+
+	srv := payostest.NewServer(&payostest.Options{PayoutBalance: 1000000})
+	t.Cleanup(srv.Close)
+
+	policy := payos.DefaultRetryPolicy{InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	client, err := payos.NewPayOS(&payos.PayOSOptions{
+		ClientId:    srv.ClientId,
+		ApiKey:      srv.ApiKey,
+		ChecksumKey: srv.ChecksumKey,
+		BaseURL:     srv.URL,
+		MaxRetries:  2,
+		RetryPolicy: payos.RetryPolicyFunc(func(ctx context.Context, attempt *payos.RetryAttempt) (time.Duration, bool) {
+			backoff, ok := policy.Retry(ctx, attempt)
+			if retryAfter != nil && ok {
+				*retryAfter = backoff
+				backoff = time.Millisecond
+			}
+			return backoff, ok
+		}),
+		Middlewares: []payos.Middleware{injector.Middleware()},
+	})
+	if err != nil {
+		t.Fatalf("NewPayOS() error = %v", err)
+	}
+	return client, srv
+}
+
+func createPaymentLink(t *testing.T, client *payos.PayOS, orderCode int64) {
+	t.Helper()
+	_, err := client.PaymentRequests.Create(context.Background(), payos.CreatePaymentLinkRequest{
+		OrderCode:   orderCode,
+		Amount:      2000,
+		Description: "chaos",
+		ReturnUrl:   "https://example.com/return",
+		CancelUrl:   "https://example.com/cancel",
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+}
+
+func TestStatusFaultIsRetried(t *testing.T) {
+	injector := chaos.New(chaos.Options{Rules: []chaos.Rule{
+		{Fault: chaos.FaultStatus, Method: http.MethodGet, Path: "/v2/payment-requests/*", StatusCode: 502, Times: 2},
+	}})
+	client, _ := newTestPayOS(t, injector, nil)
+	createPaymentLink(t, client, 1)
+
+	if _, err := client.PaymentRequests.Get(context.Background(), 1); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got := injector.Injected(chaos.FaultStatus); got != 2 {
+		t.Errorf("Injected(FaultStatus) = %d, want 2", got)
+	}
+}
+
+func TestTooManyRequestsHonorsRetryAfter(t *testing.T) {
+	injector := chaos.New(chaos.Options{Rules: []chaos.Rule{
+		{Fault: chaos.FaultStatus, Path: "/v1/payouts-account/balance", StatusCode: 429, RetryAfter: 1500 * time.Millisecond, Times: 1},
+	}})
+	var retryAfter time.Duration
+	client, _ := newTestPayOS(t, injector, &retryAfter)
+
+	if _, err := client.PayoutsAccount.Balance(context.Background()); err != nil {
+		t.Fatalf("Balance() error = %v", err)
+	}
+	if retryAfter != 2*time.Second {
+		t.Errorf("retry backoff = %v, want the 2s of Retry-After", retryAfter)
+	}
+}
+
+func TestLatencyFaultTimesOut(t *testing.T) {
+	injector := chaos.New(chaos.Options{Rules: []chaos.Rule{
+		{Fault: chaos.FaultLatency, Path: "/v1/payouts-account/balance", Latency: time.Minute},
+	}})
+	client, _ := newTestPayOS(t, injector, nil)
+
+	_, err := client.PayoutsAccount.Balance(context.Background(), payos.WithTimeout(50*time.Millisecond))
+	if !errors.Is(err, apierror.ErrConnectionTimeout) {
+		t.Errorf("Balance() error = %v, want ErrConnectionTimeout", err)
+	}
+}
+
+func TestResetFaultReplaysIdempotentPayout(t *testing.T) {
+	injector := chaos.New(chaos.Options{Rules: []chaos.Rule{
+		{Fault: chaos.FaultReset, Method: http.MethodPost, Path: "/v1/payouts", Times: 1},
+	}})
+	client, srv := newTestPayOS(t, injector, nil)
+	balance := srv.Balance()
+
+	key := "chaos-reset"
+	_, err := client.Payouts.Create(context.Background(), payos.PayoutRequest{
+		ReferenceId:     "chaos-1",
+		Amount:          50000,
+		Description:     "payout",
+		ToBin:           "970422",
+		ToAccountNumber: "0123456789",
+	}, &key)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if got := balance - srv.Balance(); got != 50000 {
+		t.Errorf("balance debited by %d, want a single payout of 50000", got)
+	}
+}
+
+func TestResetFaultError(t *testing.T) {
+	injector := chaos.New(chaos.Options{Rules: []chaos.Rule{{Fault: chaos.FaultReset}}})
+	handler := injector.Middleware()(func(ctx context.Context, req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("{}"))}, nil
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "http://payos.test/v1/payouts", nil)
+	if _, err := handler(context.Background(), req); !errors.Is(err, syscall.ECONNRESET) {
+		t.Errorf("handler() error = %v, want ECONNRESET", err)
+	}
+}
+
+func TestTruncateFault(t *testing.T) {
+	injector := chaos.New(chaos.Options{Rules: []chaos.Rule{
+		{Fault: chaos.FaultTruncate, Path: "/v2/payment-requests/*/invoices/*/download", Times: 1},
+		{Fault: chaos.FaultTruncate, Path: "/v1/payouts-account/balance", Times: 1},
+	}})
+	client, srv := newTestPayOS(t, injector, nil)
+	ctx := context.Background()
+
+	_, err := client.PayoutsAccount.Balance(ctx, payos.WithMaxRetries(0))
+	if !errors.Is(err, apierror.ErrConnection) {
+		t.Errorf("Balance() error = %v, want ErrConnection", err)
+	}
+
+	createPaymentLink(t, client, 2)
+	if err := srv.MarkPaid(2); err != nil {
+		t.Fatalf("MarkPaid() error = %v", err)
+	}
+	info, err := client.PaymentRequests.Invoices.Get(ctx, 2)
+	if err != nil {
+		t.Fatalf("Invoices.Get() error = %v", err)
+	}
+	invoiceId := info.Invoices[0].InvoiceId
+
+	var buf bytes.Buffer
+	if _, err := client.PaymentRequests.Invoices.DownloadTo(ctx, &buf, invoiceId, 2); err != nil {
+		t.Fatalf("DownloadTo() error = %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), payostest.InvoicePDF(invoiceId)) {
+		t.Error("resumed download differs from the invoice")
+	}
+	if got := injector.Injected(chaos.FaultTruncate); got != 2 {
+		t.Errorf("Injected(FaultTruncate) = %d, want 2", got)
+	}
+}
+
+func TestSignatureFaults(t *testing.T) {
+	injector := chaos.New(chaos.Options{Rules: []chaos.Rule{
+		{Fault: chaos.FaultInvalidBodySignature, Method: http.MethodPost, Path: "/v2/payment-requests"},
+		{Fault: chaos.FaultCorruptSignature, Method: http.MethodGet, Path: "/v1/payouts/*"},
+	}})
+	client, _ := newTestPayOS(t, injector, nil)
+	ctx := context.Background()
+
+	_, err := client.PaymentRequests.Create(ctx, payos.CreatePaymentLinkRequest{
+		OrderCode:   3,
+		Amount:      2000,
+		Description: "chaos",
+		ReturnUrl:   "https://example.com/return",
+		CancelUrl:   "https://example.com/cancel",
+	})
+	if !errors.Is(err, apierror.ErrInvalidSignature) {
+		t.Errorf("PaymentRequests.Create() error = %v, want ErrInvalidSignature", err)
+	}
+
+	payout, err := client.Payouts.Create(ctx, payos.PayoutRequest{
+		ReferenceId:     "chaos-2",
+		Amount:          50000,
+		Description:     "payout",
+		ToBin:           "970422",
+		ToAccountNumber: "0123456789",
+	}, nil)
+	if err != nil {
+		t.Fatalf("Payouts.Create() error = %v", err)
+	}
+	if _, err := client.Payouts.Get(ctx, payout.Id); !errors.Is(err, apierror.ErrInvalidSignature) {
+		t.Errorf("Payouts.Get() error = %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestSeedIsReproducible(t *testing.T) {
+	run := func(seed int64) []bool {
+		injector := chaos.New(chaos.Options{Seed: seed, Rules: []chaos.Rule{
+			{Fault: chaos.FaultStatus, Probability: chaos.Chance(0.5)},
+		}})
+		handler := injector.Middleware()(func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+		})
+
+		var faults []bool
+		for i := 0; i < 32; i++ {
+			req, _ := http.NewRequest(http.MethodGet, "http://payos.test/v1/payouts", nil)
+			resp, err := handler(context.Background(), req)
+			if err != nil {
+				t.Fatalf("handler() error = %v", err)
+			}
+			faults = append(faults, resp.StatusCode != http.StatusOK)
+		}
+		return faults
+	}
+
+	first, second := run(7), run(7)
+	var injected int
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("request %d: fault = %v, then %v with the same seed", i, first[i], second[i])
+		}
+		if first[i] {
+			injected++
+		}
+	}
+	if injected == 0 || injected == len(first) {
+		t.Errorf("injected %d of %d requests with probability 0.5", injected, len(first))
+	}
+}
+
+func TestZeroProbability(t *testing.T) {
+	injector := chaos.New(chaos.Options{Rules: []chaos.Rule{
+		{Fault: chaos.FaultStatus, Probability: chaos.Chance(0)},
+	}})
+	handler := injector.Middleware()(func(ctx context.Context, req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+
+	for i := 0; i < 16; i++ {
+		req, _ := http.NewRequest(http.MethodGet, "http://payos.test/v1/payouts", nil)
+		if resp, err := handler(context.Background(), req); err != nil || resp.StatusCode != http.StatusOK {
+			t.Fatalf("handler() = %v, %v with a zero probability", resp, err)
+		}
+	}
+	if n := injector.Injected(chaos.FaultStatus); n != 0 {
+		t.Errorf("Injected() = %d, want 0", n)
+	}
+}
@@ -0,0 +1,131 @@
+package payostest
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// FakeClock is a clock for payos.PayOSOptions.Clock that only moves when told to
+// Waits started with After or Sleep fire once Advance moves the clock past their deadline
+// With auto advance enabled, every wait moves the clock forward and fires at once,
+// so retries and backoffs run without waiting while Sleeps records how long they would have taken
+type FakeClock struct {
+	mu          sync.Mutex
+	now         time.Time
+	autoAdvance bool
+	waiters     []*fakeWaiter
+	sleeps      []time.Duration
+	changed     chan struct{}
+}
+
+type fakeWaiter struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+// NewFakeClock returns a FakeClock set to start
+// Start from time.Now() when the calls under test carry context deadlines
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start, changed: make(chan struct{})}
+}
+
+// SetAutoAdvance makes every wait move the clock forward by its duration and fire at once
+func (c *FakeClock) SetAutoAdvance(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.autoAdvance = enabled
+}
+
+// Now returns the time of the clock
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After returns a channel receiving the time of the clock once it reaches d from now
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sleeps = append(c.sleeps, d)
+	ch := make(chan time.Time, 1)
+	c.waiters = append(c.waiters, &fakeWaiter{deadline: c.now.Add(d), ch: ch})
+	if c.autoAdvance {
+		c.advance(max(d, 0))
+	} else {
+		c.fire()
+	}
+	c.notify()
+	return ch
+}
+
+// Sleep blocks until the clock reaches d from now
+func (c *FakeClock) Sleep(d time.Duration) {
+	<-c.After(d)
+}
+
+// Advance moves the clock forward by d and fires the waits that are due
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.advance(d)
+}
+
+// Set moves the clock to t and fires the waits that are due
+func (c *FakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.advance(t.Sub(c.now))
+}
+
+// Waiters returns the number of waits that did not fire yet
+func (c *FakeClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+// BlockUntil blocks until n waits are pending, so a test can Advance once the code under test waits
+func (c *FakeClock) BlockUntil(n int) {
+	c.mu.Lock()
+	for len(c.waiters) < n {
+		changed := c.changed
+		c.mu.Unlock()
+		<-changed
+		c.mu.Lock()
+	}
+	c.mu.Unlock()
+}
+
+// Sleeps returns the durations of every wait started so far
+func (c *FakeClock) Sleeps() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]time.Duration(nil), c.sleeps...)
+}
+
+func (c *FakeClock) advance(d time.Duration) {
+	c.now = c.now.Add(d)
+	c.fire()
+}
+
+// fire sends the time to the waits that are due, earliest first
+func (c *FakeClock) fire() {
+	sort.SliceStable(c.waiters, func(i, j int) bool {
+		return c.waiters[i].deadline.Before(c.waiters[j].deadline)
+	})
+	due := 0
+	for due < len(c.waiters) && !c.waiters[due].deadline.After(c.now) {
+		c.waiters[due].ch <- c.now
+		due++
+	}
+	c.waiters = c.waiters[due:]
+}
+
+// notify wakes BlockUntil
+func (c *FakeClock) notify() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
@@ -0,0 +1,42 @@
+package payostest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+
+	done := make(chan time.Time)
+	go func() {
+		clock.Sleep(time.Minute)
+		done <- clock.Now()
+	}()
+
+	clock.BlockUntil(1)
+	clock.Advance(30 * time.Second)
+	select {
+	case <-done:
+		t.Fatal("Sleep() returned before the clock reached its deadline")
+	default:
+	}
+
+	clock.Advance(30 * time.Second)
+	if woke := <-done; !woke.Equal(start.Add(time.Minute)) {
+		t.Errorf("Sleep() woke at %v, want %v", woke, start.Add(time.Minute))
+	}
+	if clock.Waiters() != 0 {
+		t.Errorf("Waiters() = %d, want 0", clock.Waiters())
+	}
+
+	clock.SetAutoAdvance(true)
+	<-clock.After(time.Hour)
+	if got := clock.Now(); !got.Equal(start.Add(time.Hour + time.Minute)) {
+		t.Errorf("Now() after auto advance = %v", got)
+	}
+	if sleeps := clock.Sleeps(); len(sleeps) != 2 || sleeps[1] != time.Hour {
+		t.Errorf("Sleeps() = %v", sleeps)
+	}
+}
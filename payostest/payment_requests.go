@@ -0,0 +1,258 @@
+package payostest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/payOSHQ/payos-lib-golang/v2/signature"
+)
+
+// Payment link statuses used by the fake server
+const (
+	statusPending   = "PENDING"
+	statusCancelled = "CANCELLED"
+	statusPaid      = "PAID"
+	statusExpired   = "EXPIRED"
+)
+
+// createPaymentLinkRequest mirrors the create payment link request body
+type createPaymentLinkRequest struct {
+	OrderCode   int64           `json:"orderCode"`
+	Amount      int             `json:"amount"`
+	Description string          `json:"description"`
+	CancelUrl   string          `json:"cancelUrl"`
+	ReturnUrl   string          `json:"returnUrl"`
+	Signature   *string         `json:"signature"`
+	Items       json.RawMessage `json:"items,omitempty"`
+	ExpiredAt   *int            `json:"expiredAt,omitempty"`
+}
+
+// createPaymentLinkData mirrors the create payment link response data
+type createPaymentLinkData struct {
+	Bin           string `json:"bin"`
+	AccountNumber string `json:"accountNumber"`
+	AccountName   string `json:"accountName"`
+	Amount        int    `json:"amount"`
+	Description   string `json:"description"`
+	OrderCode     int64  `json:"orderCode"`
+	Currency      string `json:"currency"`
+	PaymentLinkId string `json:"paymentLinkId"`
+	Status        string `json:"status"`
+	ExpiredAt     *int   `json:"expiredAt"`
+	CheckoutUrl   string `json:"checkoutUrl"`
+	QrCode        string `json:"qrCode"`
+}
+
+// transaction mirrors a payment link transaction
+type transaction struct {
+	Reference              string  `json:"reference"`
+	Amount                 int     `json:"amount"`
+	AccountNumber          string  `json:"accountNumber"`
+	Description            string  `json:"description"`
+	TransactionDateTime    string  `json:"transactionDateTime"`
+	VirtualAccountName     *string `json:"virtualAccountName"`
+	VirtualAccountNumber   *string `json:"virtualAccountNumber"`
+	CounterAccountBankId   *string `json:"counterAccountBankId"`
+	CounterAccountBankName *string `json:"counterAccountBankName"`
+	CounterAccountName     *string `json:"counterAccountName"`
+	CounterAccountNumber   *string `json:"counterAccountNumber"`
+}
+
+// paymentLinkData mirrors the payment link information response data
+type paymentLinkData struct {
+	Id                 string        `json:"id"`
+	OrderCode          int64         `json:"orderCode"`
+	Amount             int           `json:"amount"`
+	AmountPaid         int           `json:"amountPaid"`
+	AmountRemaining    int           `json:"amountRemaining"`
+	Status             string        `json:"status"`
+	CreatedAt          string        `json:"createdAt"`
+	Transactions       []transaction `json:"transactions"`
+	CancellationReason *string       `json:"cancellationReason"`
+	CanceledAt         *string       `json:"canceledAt"`
+}
+
+// invoice mirrors an invoice issued for a paid payment link
+type invoice struct {
+	InvoiceId       string  `json:"invoiceId"`
+	InvoiceNumber   *string `json:"invoiceNumber"`
+	IssuedTimestamp *int64  `json:"issuedTimestamp"`
+	IssuedDatetime  *string `json:"issuedDatetime"`
+	TransactionId   *string `json:"transactionId"`
+	ReservationCode *string `json:"reservationCode"`
+	CodeOfTax       *string `json:"codeOfTax"`
+}
+
+// paymentLink is the in-memory state of a payment link
+type paymentLink struct {
+	data        paymentLinkData
+	description string
+	expiredAt   *int
+	invoices    []invoice
+}
+
+// createPaymentLink handles POST /v2/payment-requests
+func (s *Server) createPaymentLink(w http.ResponseWriter, body []byte) {
+	var req createPaymentLinkRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		s.writeError(w, http.StatusBadRequest, codeInvalidParams, "invalid request body")
+		return
+	}
+	if req.OrderCode == 0 || req.Amount == 0 || req.Description == "" || req.CancelUrl == "" || req.ReturnUrl == "" {
+		s.writeError(w, http.StatusOK, codeInvalidParams, "orderCode, amount, description, cancelUrl and returnUrl are required")
+		return
+	}
+
+	if req.Signature == nil || signature.Verify(signature.SchemePaymentRequest, s.ChecksumKey, req, *req.Signature) != nil {
+		s.writeError(w, http.StatusOK, codeInvalidSignature, "signature is invalid")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.orderCodes[req.OrderCode]; exists {
+		s.writeError(w, http.StatusOK, codeOrderCodeExists, "order code already exists")
+		return
+	}
+
+	link := &paymentLink{
+		data: paymentLinkData{
+			Id:              newId(),
+			OrderCode:       req.OrderCode,
+			Amount:          req.Amount,
+			AmountRemaining: req.Amount,
+			Status:          statusPending,
+			CreatedAt:       now(),
+			Transactions:    []transaction{},
+		},
+		description: req.Description,
+		expiredAt:   req.ExpiredAt,
+	}
+	s.paymentLinks[link.data.Id] = link
+	s.orderCodes[req.OrderCode] = link.data.Id
+
+	s.writeBodySigned(w, createPaymentLinkData{
+		Bin:           s.bin,
+		AccountNumber: s.accountNumber,
+		AccountName:   s.accountName,
+		Amount:        req.Amount,
+		Description:   req.Description,
+		OrderCode:     req.OrderCode,
+		Currency:      "VND",
+		PaymentLinkId: link.data.Id,
+		Status:        statusPending,
+		ExpiredAt:     req.ExpiredAt,
+		CheckoutUrl:   fmt.Sprintf("%s/web/%s", s.URL, link.data.Id),
+		QrCode:        fmt.Sprintf("payostest|%s|%d|%s", s.accountNumber, req.Amount, req.Description),
+	})
+}
+
+// getPaymentLink handles GET /v2/payment-requests/{id}
+func (s *Server) getPaymentLink(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	link := s.findPaymentLink(id)
+	if link == nil {
+		s.writeError(w, http.StatusOK, codePaymentLinkNotFound, "payment link not found")
+		return
+	}
+	s.writeBodySigned(w, link.data)
+}
+
+// cancelPaymentLink handles POST /v2/payment-requests/{id}/cancel
+func (s *Server) cancelPaymentLink(w http.ResponseWriter, id string, body []byte) {
+	var req struct {
+		CancellationReason *string `json:"cancellationReason"`
+	}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			s.writeError(w, http.StatusBadRequest, codeInvalidParams, "invalid request body")
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	link := s.findPaymentLink(id)
+	if link == nil {
+		s.writeError(w, http.StatusOK, codePaymentLinkNotFound, "payment link not found")
+		return
+	}
+	if link.data.Status != statusPending {
+		s.writeError(w, http.StatusOK, codeLinkNotCancellable, fmt.Sprintf("payment link is %s", link.data.Status))
+		return
+	}
+
+	canceledAt := now()
+	link.data.Status = statusCancelled
+	link.data.CancellationReason = req.CancellationReason
+	link.data.CanceledAt = &canceledAt
+	s.writeBodySigned(w, link.data)
+}
+
+// getInvoices handles GET /v2/payment-requests/{id}/invoices
+func (s *Server) getInvoices(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	link := s.findPaymentLink(id)
+	if link == nil {
+		s.writeError(w, http.StatusOK, codePaymentLinkNotFound, "payment link not found")
+		return
+	}
+	invoices := link.invoices
+	if invoices == nil {
+		invoices = []invoice{}
+	}
+	s.writeBodySigned(w, map[string]interface{}{"invoices": invoices})
+}
+
+// downloadInvoice handles GET /v2/payment-requests/{id}/invoices/{invoiceId}/download
+func (s *Server) downloadInvoice(w http.ResponseWriter, r *http.Request, id, invoiceId string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	link := s.findPaymentLink(id)
+	if link == nil {
+		s.writeError(w, http.StatusOK, codePaymentLinkNotFound, "payment link not found")
+		return
+	}
+	for _, inv := range link.invoices {
+		if inv.InvoiceId != invoiceId {
+			continue
+		}
+		// ServeContent answers Range requests so downloads can be resumed
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.pdf\"", inv.InvoiceId))
+		w.Header().Set("ETag", fmt.Sprintf("\"%s\"", inv.InvoiceId))
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(InvoicePDF(inv.InvoiceId)))
+		return
+	}
+	s.writeError(w, http.StatusOK, codeInvalidParams, "invoice not found")
+}
+
+// InvoicePDF returns the deterministic document served for an invoice ID
+func InvoicePDF(invoiceId string) []byte {
+	return []byte(fmt.Sprintf("%%PDF-1.4\n%% payostest invoice %s\n%%%%EOF\n", invoiceId))
+}
+
+// findPaymentLink looks a payment link up by ID or order code
+// It must be called with s.mu held
+func (s *Server) findPaymentLink(id string) *paymentLink {
+	if link, ok := s.paymentLinks[id]; ok {
+		return link
+	}
+	if orderCode, err := strconv.ParseInt(id, 10, 64); err == nil {
+		if linkId, ok := s.orderCodes[orderCode]; ok {
+			return s.paymentLinks[linkId]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,255 @@
+package payostest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// payoutRequest mirrors a single payout request body
+type payoutRequest struct {
+	ReferenceId     string   `json:"referenceId"`
+	Amount          int      `json:"amount"`
+	Description     string   `json:"description"`
+	ToBin           string   `json:"toBin"`
+	ToAccountNumber string   `json:"toAccountNumber"`
+	Category        []string `json:"category"`
+}
+
+// payoutBatchRequest mirrors a batch payout request body
+type payoutBatchRequest struct {
+	ReferenceId string          `json:"referenceId"`
+	Category    []string        `json:"category"`
+	Payouts     []payoutRequest `json:"payouts"`
+}
+
+// payoutTransaction mirrors a payout transaction
+type payoutTransaction struct {
+	Id                  string  `json:"id"`
+	ReferenceId         string  `json:"referenceId"`
+	Amount              int     `json:"amount"`
+	Description         string  `json:"description"`
+	ToBin               string  `json:"toBin"`
+	ToAccountNumber     string  `json:"toAccountNumber"`
+	ToAccountName       *string `json:"toAccountName"`
+	Reference           *string `json:"reference"`
+	TransactionDatetime *string `json:"transactionDatetime"`
+	ErrorMessage        *string `json:"errorMessage"`
+	ErrorCode           *string `json:"errorCode"`
+	State               string  `json:"state"`
+}
+
+// payout mirrors a payout and is also its in-memory state
+type payout struct {
+	Id            string              `json:"id"`
+	ReferenceId   string              `json:"referenceId"`
+	Transactions  []payoutTransaction `json:"transactions"`
+	Category      []string            `json:"category"`
+	ApprovalState string              `json:"approvalState"`
+	CreatedAt     string              `json:"createdAt"`
+}
+
+// createPayout handles POST /v1/payouts
+func (s *Server) createPayout(w http.ResponseWriter, r *http.Request, body []byte) {
+	var req payoutRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		s.writeError(w, http.StatusBadRequest, codeInvalidParams, "invalid request body")
+		return
+	}
+	s.handlePayout(w, r, body, req.ReferenceId, req.Category, []payoutRequest{req})
+}
+
+// createPayoutBatch handles POST /v1/payouts/batch
+func (s *Server) createPayoutBatch(w http.ResponseWriter, r *http.Request, body []byte) {
+	var req payoutBatchRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		s.writeError(w, http.StatusBadRequest, codeInvalidParams, "invalid request body")
+		return
+	}
+	s.handlePayout(w, r, body, req.ReferenceId, req.Category, req.Payouts)
+}
+
+// handlePayout validates, deduplicates and records a single or batch payout
+func (s *Server) handlePayout(w http.ResponseWriter, r *http.Request, body []byte, referenceId string, category []string, items []payoutRequest) {
+	if !s.verifyHeaderSignature(r, body) {
+		s.writeError(w, http.StatusBadRequest, codeInvalidSignature, "signature is invalid")
+		return
+	}
+	key := r.Header.Get("x-idempotency-key")
+	if key == "" {
+		s.writeError(w, http.StatusBadRequest, codeInvalidParams, "x-idempotency-key header is required")
+		return
+	}
+	if referenceId == "" || len(items) == 0 {
+		s.writeError(w, http.StatusBadRequest, codeInvalidParams, "referenceId and payouts are required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.replayIdempotent(w, key, body) {
+		return
+	}
+
+	total := 0
+	for _, item := range items {
+		if item.Amount <= 0 || item.ToBin == "" || item.ToAccountNumber == "" {
+			s.writeError(w, http.StatusBadRequest, codeInvalidParams, "amount, toBin and toAccountNumber are required")
+			return
+		}
+		if !isDigits(item.ToBin) || !isDigits(item.ToAccountNumber) {
+			s.writeError(w, http.StatusBadRequest, codeInvalidParams, "toBin and toAccountNumber must be numeric")
+			return
+		}
+		total += item.Amount
+	}
+	if total > s.balance {
+		s.writeError(w, http.StatusBadRequest, codeInvalidParams, "insufficient balance")
+		return
+	}
+	s.balance -= total
+
+	if category == nil {
+		category = []string{}
+	}
+	createdAt := now()
+	p := &payout{
+		Id:            newId(),
+		ReferenceId:   referenceId,
+		Category:      category,
+		ApprovalState: "COMPLETED",
+		CreatedAt:     createdAt,
+	}
+	for _, item := range items {
+		reference := newId()[:12]
+		p.Transactions = append(p.Transactions, payoutTransaction{
+			Id:                  newId(),
+			ReferenceId:         item.ReferenceId,
+			Amount:              item.Amount,
+			Description:         item.Description,
+			ToBin:               item.ToBin,
+			ToAccountNumber:     item.ToAccountNumber,
+			Reference:           &reference,
+			TransactionDatetime: &createdAt,
+			State:               "SUCCEEDED",
+		})
+	}
+	s.payouts = append(s.payouts, p)
+	s.payoutsById[p.Id] = p
+
+	responseBody := s.writeHeaderSigned(w, p)
+	s.storeIdempotent(w, key, body, responseBody)
+}
+
+// estimateCredit handles POST /v1/payouts/estimate-credit
+func (s *Server) estimateCredit(w http.ResponseWriter, r *http.Request, body []byte) {
+	if !s.verifyHeaderSignature(r, body) {
+		s.writeError(w, http.StatusBadRequest, codeInvalidSignature, "signature is invalid")
+		return
+	}
+
+	var req struct {
+		payoutRequest
+		Payouts []payoutRequest `json:"payouts"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		s.writeError(w, http.StatusBadRequest, codeInvalidParams, "invalid request body")
+		return
+	}
+
+	total := req.Amount
+	for _, item := range req.Payouts {
+		total += item.Amount
+	}
+	s.writeUnsigned(w, map[string]interface{}{"estimateCredit": total})
+}
+
+// getPayout handles GET /v1/payouts/{id}
+func (s *Server) getPayout(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.payoutsById[id]
+	if !ok {
+		s.writeError(w, http.StatusNotFound, codeInvalidParams, "payout not found")
+		return
+	}
+	s.writeHeaderSigned(w, p)
+}
+
+// listPayouts handles GET /v1/payouts
+func (s *Server) listPayouts(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit := 10
+	offset := 0
+	if v, err := strconv.Atoi(query.Get("limit")); err == nil && v > 0 {
+		limit = v
+	}
+	if v, err := strconv.Atoi(query.Get("offset")); err == nil && v >= 0 {
+		offset = v
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	matched := []*payout{}
+	for _, p := range s.payouts {
+		if referenceId := query.Get("referenceId"); referenceId != "" && p.ReferenceId != referenceId {
+			continue
+		}
+		if approvalState := query.Get("approvalState"); approvalState != "" && p.ApprovalState != approvalState {
+			continue
+		}
+		if category := query.Get("category"); category != "" && !containsAny(p.Category, strings.Split(category, ",")) {
+			continue
+		}
+		matched = append(matched, p)
+	}
+
+	page := []*payout{}
+	if offset < len(matched) {
+		end := offset + limit
+		if end > len(matched) {
+			end = len(matched)
+		}
+		page = matched[offset:end]
+	}
+
+	s.writeHeaderSigned(w, map[string]interface{}{
+		"pagination": map[string]interface{}{
+			"limit":   limit,
+			"offset":  offset,
+			"total":   len(matched),
+			"count":   len(page),
+			"hasMore": offset+len(page) < len(matched),
+		},
+		"payouts": page,
+	})
+}
+
+// getBalance handles GET /v1/payouts-account/balance
+func (s *Server) getBalance(w http.ResponseWriter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.writeHeaderSigned(w, map[string]interface{}{
+		"accountNumber": s.accountNumber,
+		"accountName":   s.accountName,
+		"currency":      "VND",
+		"balance":       strconv.Itoa(s.balance),
+	})
+}
+
+// containsAny reports whether values shares at least one element with candidates
+func containsAny(values, candidates []string) bool {
+	for _, v := range values {
+		for _, c := range candidates {
+			if v == c {
+				return true
+			}
+		}
+	}
+	return false
+}
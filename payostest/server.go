@@ -0,0 +1,387 @@
+// Package payostest provides an in-process fake payOS Merchant API for offline tests.
+//
+// The fake server keeps payment links and payouts in memory, signs its
+// responses exactly like the real API and exposes hooks to drive payment
+// links through their lifecycle and to deliver signed webhooks:
+//
+//	srv := payostest.NewServer(nil)
+//	defer srv.Close()
+//
+//	client, _ := payos.NewPayOS(&payos.PayOSOptions{
+//	    ClientId:    srv.ClientId,
+//	    ApiKey:      srv.ApiKey,
+//	    ChecksumKey: srv.ChecksumKey,
+//	    BaseURL:     srv.URL,
+//	})
+package payostest
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/payOSHQ/payos-lib-golang/v2/apierror"
+	"github.com/payOSHQ/payos-lib-golang/v2/signature"
+)
+
+const (
+	defaultClientId      = "payostest-client-id"
+	defaultApiKey        = "payostest-api-key"
+	defaultChecksumKey   = "payostest-checksum-key"
+	defaultBin           = "970422"
+	defaultAccountNumber = "0123456789"
+	defaultAccountName   = "PAYOS TEST"
+	defaultBalance       = 100000000
+)
+
+// Response codes returned by the fake server, taken from the apierror catalog
+const (
+	codeSuccess             = apierror.CodeSuccess
+	codeInvalidParams       = apierror.CodeInvalidParams
+	codeUnauthorized        = apierror.CodeUnauthorized
+	codePaymentLinkNotFound = apierror.CodePaymentLinkNotFound
+	codeInvalidSignature    = apierror.CodeInvalidSignature
+	codeOrderCodeExists     = apierror.CodeOrderCodeExists
+	codeLinkNotCancellable  = apierror.CodePaymentLinkNotCancelable
+)
+
+// Options configures the fake server
+type Options struct {
+	// ClientId, ApiKey and ChecksumKey are the credentials accepted by the server
+	// Defaults to fixed test credentials
+	ClientId    string
+	ApiKey      string
+	ChecksumKey string
+
+	// PayoutClientId, PayoutApiKey and PayoutChecksumKey are the credentials of the payout endpoints,
+	// like the separate payout channel of payOS
+	// Default to ClientId, ApiKey and ChecksumKey
+	PayoutClientId    string
+	PayoutApiKey      string
+	PayoutChecksumKey string
+
+	// Bin, AccountNumber and AccountName describe the receiving bank account
+	Bin           string
+	AccountNumber string
+	AccountName   string
+
+	// PayoutBalance is the initial payout account balance
+	// Defaults to 100,000,000
+	PayoutBalance int
+}
+
+// Server is a fake payOS Merchant API backed by an httptest.Server
+type Server struct {
+	*httptest.Server
+
+	ClientId    string
+	ApiKey      string
+	ChecksumKey string
+
+	PayoutClientId    string
+	PayoutApiKey      string
+	PayoutChecksumKey string
+
+	bin           string
+	accountNumber string
+	accountName   string
+
+	mu           sync.Mutex
+	paymentLinks map[string]*paymentLink
+	orderCodes   map[int64]string
+	payouts      []*payout
+	payoutsById  map[string]*payout
+	idempotency  map[string]idempotentResponse
+	balance      int
+	webhookURL   string
+}
+
+// NewServer starts a fake payOS server with the provided options
+// The caller must call Close when finished
+func NewServer(opts *Options) *Server {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	s := &Server{
+		ClientId:      getValue(opts.ClientId, defaultClientId),
+		ApiKey:        getValue(opts.ApiKey, defaultApiKey),
+		ChecksumKey:   getValue(opts.ChecksumKey, defaultChecksumKey),
+		bin:           getValue(opts.Bin, defaultBin),
+		accountNumber: getValue(opts.AccountNumber, defaultAccountNumber),
+		accountName:   getValue(opts.AccountName, defaultAccountName),
+		paymentLinks:  make(map[string]*paymentLink),
+		orderCodes:    make(map[int64]string),
+		payoutsById:   make(map[string]*payout),
+		idempotency:   make(map[string]idempotentResponse),
+		balance:       defaultBalance,
+	}
+	s.PayoutClientId = getValue(opts.PayoutClientId, s.ClientId)
+	s.PayoutApiKey = getValue(opts.PayoutApiKey, s.ApiKey)
+	s.PayoutChecksumKey = getValue(opts.PayoutChecksumKey, s.ChecksumKey)
+	if opts.PayoutBalance != 0 {
+		s.balance = opts.PayoutBalance
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// WebhookURL returns the webhook URL registered through /confirm-webhook
+func (s *Server) WebhookURL() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.webhookURL
+}
+
+// Balance returns the current payout account balance
+func (s *Server) Balance() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.balance
+}
+
+// serveHTTP authenticates the request and routes it to the matching endpoint
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	clientId, apiKey := s.ClientId, s.ApiKey
+	if strings.HasPrefix(r.URL.Path, "/v1/payouts") {
+		clientId, apiKey = s.PayoutClientId, s.PayoutApiKey
+	}
+	if r.Header.Get("x-client-id") != clientId || r.Header.Get("x-api-key") != apiKey {
+		s.writeError(w, http.StatusUnauthorized, codeUnauthorized, "Unauthorized")
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, codeInvalidParams, "failed to read body")
+		return
+	}
+
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+
+	switch {
+	case r.Method == http.MethodPost && path == "/v2/payment-requests":
+		s.createPaymentLink(w, body)
+	case r.Method == http.MethodGet && len(segments) == 3 && hasPrefix(segments, "v2", "payment-requests"):
+		s.getPaymentLink(w, segments[2])
+	case r.Method == http.MethodPost && len(segments) == 4 && hasPrefix(segments, "v2", "payment-requests") && segments[3] == "cancel":
+		s.cancelPaymentLink(w, segments[2], body)
+	case r.Method == http.MethodGet && len(segments) == 4 && hasPrefix(segments, "v2", "payment-requests") && segments[3] == "invoices":
+		s.getInvoices(w, segments[2])
+	case r.Method == http.MethodGet && len(segments) == 6 && hasPrefix(segments, "v2", "payment-requests") && segments[3] == "invoices" && segments[5] == "download":
+		s.downloadInvoice(w, r, segments[2], segments[4])
+	case r.Method == http.MethodPost && path == "/v1/payouts":
+		s.createPayout(w, r, body)
+	case r.Method == http.MethodPost && path == "/v1/payouts/batch":
+		s.createPayoutBatch(w, r, body)
+	case r.Method == http.MethodPost && path == "/v1/payouts/estimate-credit":
+		s.estimateCredit(w, r, body)
+	case r.Method == http.MethodGet && path == "/v1/payouts":
+		s.listPayouts(w, r)
+	case r.Method == http.MethodGet && len(segments) == 3 && hasPrefix(segments, "v1", "payouts"):
+		s.getPayout(w, segments[2])
+	case r.Method == http.MethodGet && path == "/v1/payouts-account/balance":
+		s.getBalance(w)
+	case r.Method == http.MethodPost && path == "/confirm-webhook":
+		s.confirmWebhook(w, body)
+	default:
+		s.writeError(w, http.StatusNotFound, codeInvalidParams, "route not found")
+	}
+}
+
+// ========================
+// Response helpers
+// ========================
+
+// envelope is the standard payOS response wrapper
+type envelope struct {
+	Code      string      `json:"code"`
+	Desc      string      `json:"desc"`
+	Data      interface{} `json:"data"`
+	Signature *string     `json:"signature,omitempty"`
+}
+
+// writeBodySigned writes data signed in the response body like payment request endpoints
+func (s *Server) writeBodySigned(w http.ResponseWriter, data interface{}) {
+	sig, err := signature.Sign(signature.SchemeBody, s.ChecksumKey, data)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, codeInvalidParams, err.Error())
+		return
+	}
+	s.writeJSON(w, http.StatusOK, nil, envelope{Code: codeSuccess, Desc: "success", Data: data, Signature: &sig})
+}
+
+// writeHeaderSigned writes data signed in the x-signature header like payout endpoints
+func (s *Server) writeHeaderSigned(w http.ResponseWriter, data interface{}) []byte {
+	sig, err := signature.Sign(signature.SchemeHeader, s.PayoutChecksumKey, data)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, codeInvalidParams, err.Error())
+		return nil
+	}
+	header := http.Header{}
+	header.Set("x-signature", sig)
+	return s.writeJSON(w, http.StatusOK, header, envelope{Code: codeSuccess, Desc: "success", Data: data})
+}
+
+// writeUnsigned writes data without any signature
+func (s *Server) writeUnsigned(w http.ResponseWriter, data interface{}) {
+	s.writeJSON(w, http.StatusOK, nil, envelope{Code: codeSuccess, Desc: "success", Data: data})
+}
+
+// writeError writes a payOS error response with a null data field
+func (s *Server) writeError(w http.ResponseWriter, status int, code, desc string) {
+	s.writeJSON(w, status, nil, envelope{Code: code, Desc: desc})
+}
+
+// writeJSON writes the envelope and returns the encoded body
+func (s *Server) writeJSON(w http.ResponseWriter, status int, header http.Header, resp envelope) []byte {
+	body, err := json.Marshal(resp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil
+	}
+	for key, values := range header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+	return body
+}
+
+// verifyHeaderSignature checks the x-signature header of a payout request
+func (s *Server) verifyHeaderSignature(r *http.Request, body []byte) bool {
+	return signature.Verify(signature.SchemeHeader, s.PayoutChecksumKey, json.RawMessage(body), r.Header.Get("x-signature")) == nil
+}
+
+// ========================
+// Idempotency
+// ========================
+
+// idempotentResponse is a cached response replayed for a repeated idempotency key
+type idempotentResponse struct {
+	requestHash string
+	signature   string
+	body        []byte
+}
+
+// replayIdempotent replays the cached response for key and reports whether it handled the request
+// It must be called with s.mu held
+func (s *Server) replayIdempotent(w http.ResponseWriter, key string, body []byte) bool {
+	cached, ok := s.idempotency[key]
+	if !ok {
+		return false
+	}
+	if cached.requestHash != hashBody(body) {
+		s.writeError(w, http.StatusConflict, codeInvalidParams, "idempotency key reused with a different request body")
+		return true
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("x-signature", cached.signature)
+	w.WriteHeader(http.StatusOK)
+	w.Write(cached.body)
+	return true
+}
+
+// storeIdempotent caches the response written for key
+// It must be called with s.mu held
+func (s *Server) storeIdempotent(w http.ResponseWriter, key string, requestBody, responseBody []byte) {
+	if responseBody == nil {
+		return
+	}
+	s.idempotency[key] = idempotentResponse{
+		requestHash: hashBody(requestBody),
+		signature:   w.Header().Get("x-signature"),
+		body:        responseBody,
+	}
+}
+
+// ========================
+// Helpers
+// ========================
+
+// getValue returns the first non-empty string value
+func getValue(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// hasPrefix reports whether segments starts with the given path segments
+func hasPrefix(segments []string, prefix ...string) bool {
+	if len(segments) < len(prefix) {
+		return false
+	}
+	for i, p := range prefix {
+		if segments[i] != p {
+			return false
+		}
+	}
+	return true
+}
+
+// isDigits reports whether s only contains ASCII digits
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// newId returns a random 32 character hex identifier
+func newId() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("payostest: failed to generate id: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
+
+// hashBody returns a stable hash of a request body
+func hashBody(body []byte) string {
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, body); err != nil {
+		compact.Reset()
+		compact.Write(body)
+	}
+	sum := sha256.Sum256(compact.Bytes())
+	return hex.EncodeToString(sum[:])
+}
+
+// formatId converts a payment link ID or order code to its string form
+func formatId(id interface{}) (string, error) {
+	switch v := id.(type) {
+	case string:
+		return v, nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	default:
+		return "", fmt.Errorf("payostest: id must be string or number")
+	}
+}
+
+// now returns the current time formatted like payOS timestamps
+func now() string {
+	return time.Now().Format(time.RFC3339)
+}
@@ -0,0 +1,328 @@
+package payostest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/payOSHQ/payos-lib-golang/v2/signature"
+)
+
+func doRequest(t *testing.T, srv *Server, method, path string, body interface{}, header http.Header) (*http.Response, envelope) {
+	t.Helper()
+
+	payload, ok := body.([]byte)
+	if !ok {
+		payload, _ = json.Marshal(body)
+	}
+	req, _ := http.NewRequest(method, srv.URL+path, bytes.NewReader(payload))
+	req.Header.Set("x-client-id", srv.ClientId)
+	req.Header.Set("x-api-key", srv.ApiKey)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	var env envelope
+	json.NewDecoder(resp.Body).Decode(&env)
+	return resp, env
+}
+
+// signPayout returns the headers of a payout request signed with the payout checksum key
+func signPayout(srv *Server, body interface{}, idempotencyKey string) http.Header {
+	sig, _ := signature.Sign(signature.SchemeHeader, srv.PayoutChecksumKey, body)
+	header := http.Header{"X-Signature": {sig}}
+	if idempotencyKey != "" {
+		header.Set("X-Idempotency-Key", idempotencyKey)
+	}
+	return header
+}
+
+// newPaymentLinkRequest returns a signed create payment link request body
+func newPaymentLinkRequest(srv *Server, orderCode int64) createPaymentLinkRequest {
+	req := createPaymentLinkRequest{
+		OrderCode:   orderCode,
+		Amount:      2000,
+		Description: "payostest",
+		CancelUrl:   "https://example.com/cancel",
+		ReturnUrl:   "https://example.com/return",
+	}
+	sig, _ := signature.Sign(signature.SchemePaymentRequest, srv.ChecksumKey, req)
+	req.Signature = &sig
+	return req
+}
+
+// createLink creates a payment link and returns its ID
+func createLink(t *testing.T, srv *Server, orderCode int64) string {
+	t.Helper()
+	_, env := doRequest(t, srv, http.MethodPost, "/v2/payment-requests", newPaymentLinkRequest(srv, orderCode), nil)
+	data, _ := env.Data.(map[string]interface{})
+	id, _ := data["paymentLinkId"].(string)
+	if env.Code != codeSuccess || id == "" {
+		t.Fatalf("create payment link %d = %s %s", orderCode, env.Code, env.Desc)
+	}
+	return id
+}
+
+func TestServerRejectsInvalidCredentials(t *testing.T) {
+	srv := NewServer(&Options{PayoutClientId: "payout-id", PayoutApiKey: "payout-key"})
+	defer srv.Close()
+
+	tests := []struct {
+		name   string
+		path   string
+		header http.Header
+	}{
+		{"missing credentials", "/v2/payment-requests/1", http.Header{"X-Client-Id": {""}, "X-Api-Key": {""}}},
+		{"wrong API key", "/v2/payment-requests/1", http.Header{"X-Api-Key": {"wrong"}}},
+		{"payment keys on payouts", "/v1/payouts-account/balance", nil},
+		{"payout keys on payment requests", "/v2/payment-requests/1", http.Header{"X-Client-Id": {"payout-id"}, "X-Api-Key": {"payout-key"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, env := doRequest(t, srv, http.MethodGet, tt.path, nil, tt.header)
+			if resp.StatusCode != http.StatusUnauthorized || env.Code != codeUnauthorized {
+				t.Errorf("response = %d %s, want %d %s", resp.StatusCode, env.Code, http.StatusUnauthorized, codeUnauthorized)
+			}
+		})
+	}
+
+	payoutAuth := http.Header{"X-Client-Id": {"payout-id"}, "X-Api-Key": {"payout-key"}}
+	if resp, env := doRequest(t, srv, http.MethodGet, "/v1/payouts-account/balance", nil, payoutAuth); resp.StatusCode != http.StatusOK || env.Code != codeSuccess {
+		t.Errorf("balance with payout keys = %d %s", resp.StatusCode, env.Code)
+	}
+}
+
+func TestServerRoutes(t *testing.T) {
+	srv := NewServer(&Options{PayoutBalance: 100000})
+	defer srv.Close()
+
+	pendingId := createLink(t, srv, 1)
+	cancelledId := createLink(t, srv, 2)
+	if _, env := doRequest(t, srv, http.MethodPost, "/v2/payment-requests/"+cancelledId+"/cancel", nil, nil); env.Code != codeSuccess {
+		t.Fatalf("cancel = %s %s", env.Code, env.Desc)
+	}
+
+	unsignedLink := newPaymentLinkRequest(srv, 3)
+	unsignedLink.Signature = nil
+	badSignatureLink := newPaymentLinkRequest(srv, 3)
+	badSignatureLink.Amount = 1000
+	missingFields := newPaymentLinkRequest(srv, 3)
+	missingFields.ReturnUrl = ""
+
+	payout := map[string]interface{}{"referenceId": "p1", "amount": 1000, "description": "d", "toBin": "970422", "toAccountNumber": "1"}
+	noReference := map[string]interface{}{"amount": 1000, "toBin": "970422", "toAccountNumber": "1"}
+	nonNumeric := map[string]interface{}{"referenceId": "p2", "amount": 1000, "toBin": "970422", "toAccountNumber": "ACC-1"}
+	tooLarge := map[string]interface{}{"referenceId": "p3", "amount": 200000, "toBin": "970422", "toAccountNumber": "1"}
+	batch := map[string]interface{}{"referenceId": "b1", "payouts": []map[string]interface{}{payout, payout}}
+	estimate := map[string]interface{}{"referenceId": "e1", "payouts": []map[string]interface{}{payout}}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   interface{}
+		header http.Header
+		status int
+		code   string
+	}{
+		{"unknown route", http.MethodGet, "/v2/unknown", nil, nil, http.StatusNotFound, codeInvalidParams},
+		{"wrong method", http.MethodDelete, "/v2/payment-requests/" + pendingId, nil, nil, http.StatusNotFound, codeInvalidParams},
+
+		{"create malformed body", http.MethodPost, "/v2/payment-requests", []byte("{"), nil, http.StatusBadRequest, codeInvalidParams},
+		{"create missing fields", http.MethodPost, "/v2/payment-requests", missingFields, nil, http.StatusOK, codeInvalidParams},
+		{"create without signature", http.MethodPost, "/v2/payment-requests", unsignedLink, nil, http.StatusOK, codeInvalidSignature},
+		{"create with mismatched signature", http.MethodPost, "/v2/payment-requests", badSignatureLink, nil, http.StatusOK, codeInvalidSignature},
+		{"create existing order code", http.MethodPost, "/v2/payment-requests", newPaymentLinkRequest(srv, 1), nil, http.StatusOK, codeOrderCodeExists},
+
+		{"get by ID", http.MethodGet, "/v2/payment-requests/" + pendingId, nil, nil, http.StatusOK, codeSuccess},
+		{"get by order code with trailing slash", http.MethodGet, "/v2/payment-requests/1/", nil, nil, http.StatusOK, codeSuccess},
+		{"get unknown", http.MethodGet, "/v2/payment-requests/404", nil, nil, http.StatusOK, codePaymentLinkNotFound},
+		{"cancel malformed body", http.MethodPost, "/v2/payment-requests/1/cancel", []byte("{"), nil, http.StatusBadRequest, codeInvalidParams},
+		{"cancel unknown", http.MethodPost, "/v2/payment-requests/404/cancel", nil, nil, http.StatusOK, codePaymentLinkNotFound},
+		{"cancel cancelled", http.MethodPost, "/v2/payment-requests/" + cancelledId + "/cancel", nil, nil, http.StatusOK, codeLinkNotCancellable},
+		{"invoices of a pending link", http.MethodGet, "/v2/payment-requests/1/invoices", nil, nil, http.StatusOK, codeSuccess},
+		{"invoices unknown", http.MethodGet, "/v2/payment-requests/404/invoices", nil, nil, http.StatusOK, codePaymentLinkNotFound},
+		{"download unknown link", http.MethodGet, "/v2/payment-requests/404/invoices/inv/download", nil, nil, http.StatusOK, codePaymentLinkNotFound},
+		{"download unknown invoice", http.MethodGet, "/v2/payment-requests/1/invoices/inv/download", nil, nil, http.StatusOK, codeInvalidParams},
+
+		{"payout malformed body", http.MethodPost, "/v1/payouts", []byte("{"), nil, http.StatusBadRequest, codeInvalidParams},
+		{"payout without signature", http.MethodPost, "/v1/payouts", payout, http.Header{"X-Idempotency-Key": {"k0"}}, http.StatusBadRequest, codeInvalidSignature},
+		{"payout signed with another key", http.MethodPost, "/v1/payouts", payout, http.Header{"X-Signature": {"0000"}, "X-Idempotency-Key": {"k0"}}, http.StatusBadRequest, codeInvalidSignature},
+		{"payout without idempotency key", http.MethodPost, "/v1/payouts", payout, signPayout(srv, payout, ""), http.StatusBadRequest, codeInvalidParams},
+		{"payout without reference", http.MethodPost, "/v1/payouts", noReference, signPayout(srv, noReference, "k1"), http.StatusBadRequest, codeInvalidParams},
+		{"payout to a non-numeric account", http.MethodPost, "/v1/payouts", nonNumeric, signPayout(srv, nonNumeric, "k2"), http.StatusBadRequest, codeInvalidParams},
+		{"payout over the balance", http.MethodPost, "/v1/payouts", tooLarge, signPayout(srv, tooLarge, "k3"), http.StatusBadRequest, codeInvalidParams},
+		{"payout", http.MethodPost, "/v1/payouts", payout, signPayout(srv, payout, "k4"), http.StatusOK, codeSuccess},
+		{"batch payout", http.MethodPost, "/v1/payouts/batch", batch, signPayout(srv, batch, "k5"), http.StatusOK, codeSuccess},
+		{"estimate credit without signature", http.MethodPost, "/v1/payouts/estimate-credit", estimate, nil, http.StatusBadRequest, codeInvalidSignature},
+		{"estimate credit", http.MethodPost, "/v1/payouts/estimate-credit", estimate, signPayout(srv, estimate, ""), http.StatusOK, codeSuccess},
+		{"get unknown payout", http.MethodGet, "/v1/payouts/404", nil, nil, http.StatusNotFound, codeInvalidParams},
+		{"list payouts", http.MethodGet, "/v1/payouts?referenceId=p1", nil, nil, http.StatusOK, codeSuccess},
+		{"balance", http.MethodGet, "/v1/payouts-account/balance", nil, nil, http.StatusOK, codeSuccess},
+
+		{"confirm webhook without URL", http.MethodPost, "/confirm-webhook", map[string]string{}, nil, http.StatusBadRequest, codeInvalidParams},
+		{"confirm webhook", http.MethodPost, "/confirm-webhook", map[string]string{"webhookUrl": "https://example.com/webhook"}, nil, http.StatusOK, codeSuccess},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, env := doRequest(t, srv, tt.method, tt.path, tt.body, tt.header)
+			if resp.StatusCode != tt.status || env.Code != tt.code {
+				t.Errorf("response = %d %s (%s), want %d %s", resp.StatusCode, env.Code, env.Desc, tt.status, tt.code)
+			}
+		})
+	}
+
+	// Only the single and batch payouts were debited
+	if got := srv.Balance(); got != 100000-3*1000 {
+		t.Errorf("Balance() = %d, want %d", got, 100000-3*1000)
+	}
+	if got := srv.WebhookURL(); got != "https://example.com/webhook" {
+		t.Errorf("WebhookURL() = %q", got)
+	}
+}
+
+func TestServerSignsResponses(t *testing.T) {
+	srv := NewServer(&Options{PayoutChecksumKey: "payout-checksum-key"})
+	defer srv.Close()
+
+	// Payment request responses are signed in the body with the checksum key
+	id := createLink(t, srv, 1)
+	_, env := doRequest(t, srv, http.MethodGet, "/v2/payment-requests/"+id, nil, nil)
+	if env.Signature == nil || signature.Verify(signature.SchemeBody, srv.ChecksumKey, env.Data, *env.Signature) != nil {
+		t.Errorf("payment link signature %v does not verify", env.Signature)
+	}
+
+	// Payout responses are signed in the x-signature header with the payout checksum key
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/v1/payouts-account/balance", nil)
+	req.Header.Set("x-client-id", srv.PayoutClientId)
+	req.Header.Set("x-api-key", srv.PayoutApiKey)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request error = %v", err)
+	}
+	defer resp.Body.Close()
+	var raw struct {
+		Data json.RawMessage `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&raw)
+	if err := signature.Verify(signature.SchemeHeader, "payout-checksum-key", raw.Data, resp.Header.Get("x-signature")); err != nil {
+		t.Errorf("balance x-signature does not verify: %v", err)
+	}
+}
+
+func TestServerPaymentLinkLifecycle(t *testing.T) {
+	srv := NewServer(nil)
+	defer srv.Close()
+	id := createLink(t, srv, 10)
+
+	if _, err := srv.WebhookPayload(id); err == nil {
+		t.Error("WebhookPayload() of an unpaid link succeeded")
+	}
+	if err := srv.MarkPaid(int64(10)); err != nil {
+		t.Fatalf("MarkPaid() error = %v", err)
+	}
+	if err := srv.MarkPaid(id); err == nil {
+		t.Error("MarkPaid() of a paid link succeeded")
+	}
+	if err := srv.MarkExpired(id); err == nil {
+		t.Error("MarkExpired() of a paid link succeeded")
+	}
+	if err := srv.MarkPaid(404); err == nil {
+		t.Error("MarkPaid() of an unknown link succeeded")
+	}
+
+	_, env := doRequest(t, srv, http.MethodGet, "/v2/payment-requests/"+id, nil, nil)
+	if data, _ := env.Data.(map[string]interface{}); data["status"] != statusPaid || data["amountRemaining"] != float64(0) {
+		t.Errorf("paid link = %v", env.Data)
+	}
+	if _, env := doRequest(t, srv, http.MethodPost, "/v2/payment-requests/"+id+"/cancel", nil, nil); env.Code != codeLinkNotCancellable {
+		t.Errorf("cancel of a paid link code = %s", env.Code)
+	}
+
+	// A paid link has one invoice, served with Range support
+	_, env = doRequest(t, srv, http.MethodGet, "/v2/payment-requests/"+id+"/invoices", nil, nil)
+	invoices, _ := env.Data.(map[string]interface{})["invoices"].([]interface{})
+	if len(invoices) != 1 {
+		t.Fatalf("invoices = %v", env.Data)
+	}
+	invoiceId, _ := invoices[0].(map[string]interface{})["invoiceId"].(string)
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/v2/payment-requests/"+id+"/invoices/"+invoiceId+"/download", nil)
+	req.Header.Set("x-client-id", srv.ClientId)
+	req.Header.Set("x-api-key", srv.ApiKey)
+	req.Header.Set("Range", "bytes=4-")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("download error = %v", err)
+	}
+	pdf, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent || !bytes.Equal(pdf, InvoicePDF(invoiceId)[4:]) {
+		t.Errorf("ranged download = %d %q", resp.StatusCode, pdf)
+	}
+
+	// The webhook is signed with the checksum key and delivered to the confirmed URL
+	if _, err := srv.SendWebhook(context.Background(), id); err == nil {
+		t.Error("SendWebhook() without a registered URL succeeded")
+	}
+	var received webhook
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer receiver.Close()
+	doRequest(t, srv, http.MethodPost, "/confirm-webhook", map[string]string{"webhookUrl": receiver.URL}, nil)
+
+	status, err := srv.SendWebhook(context.Background(), int64(10))
+	if err != nil || status != http.StatusAccepted {
+		t.Fatalf("SendWebhook() = %d, %v", status, err)
+	}
+	if received.Data.PaymentLinkId != id || received.Data.Amount != 2000 {
+		t.Errorf("delivered webhook data = %+v", received.Data)
+	}
+	if err := signature.Verify(signature.SchemeBody, srv.ChecksumKey, received.Data, received.Signature); err != nil {
+		t.Errorf("delivered webhook signature does not verify: %v", err)
+	}
+
+	expiring := createLink(t, srv, 11)
+	if err := srv.MarkExpired(expiring); err != nil {
+		t.Fatalf("MarkExpired() error = %v", err)
+	}
+	if err := srv.MarkPaid(expiring); err == nil {
+		t.Error("MarkPaid() of an expired link succeeded")
+	}
+}
+
+func TestServerIdempotencyConflict(t *testing.T) {
+	srv := NewServer(&Options{PayoutBalance: 100000})
+	defer srv.Close()
+
+	sign := func(body map[string]interface{}) http.Header {
+		return signPayout(srv, body, "key-1")
+	}
+	body := map[string]interface{}{"referenceId": "r1", "amount": 60000, "description": "d", "toBin": "970422", "toAccountNumber": "1"}
+
+	if _, env := doRequest(t, srv, http.MethodPost, "/v1/payouts", body, sign(body)); env.Code != codeSuccess {
+		t.Fatalf("first payout code = %s", env.Code)
+	}
+	if _, env := doRequest(t, srv, http.MethodPost, "/v1/payouts", body, sign(body)); env.Code != codeSuccess {
+		t.Errorf("replayed payout code = %s, want %s", env.Code, codeSuccess)
+	}
+	if srv.Balance() != 40000 {
+		t.Errorf("Balance() = %d, want 40000", srv.Balance())
+	}
+
+	body["amount"] = 1000
+	if resp, env := doRequest(t, srv, http.MethodPost, "/v1/payouts", body, sign(body)); resp.StatusCode != http.StatusConflict || env.Code != codeInvalidParams {
+		t.Errorf("conflicting payout = %d %s", resp.StatusCode, env.Code)
+	}
+}
@@ -0,0 +1,203 @@
+package payostest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/payOSHQ/payos-lib-golang/v2/signature"
+)
+
+// webhookData mirrors the data field of a payment webhook
+type webhookData struct {
+	OrderCode              int64   `json:"orderCode"`
+	Amount                 int     `json:"amount"`
+	Description            string  `json:"description"`
+	AccountNumber          string  `json:"accountNumber"`
+	Reference              string  `json:"reference"`
+	TransactionDateTime    string  `json:"transactionDateTime"`
+	Currency               string  `json:"currency"`
+	PaymentLinkId          string  `json:"paymentLinkId"`
+	Code                   string  `json:"code"`
+	Desc                   string  `json:"desc"`
+	CounterAccountBankId   *string `json:"counterAccountBankId"`
+	CounterAccountBankName *string `json:"counterAccountBankName"`
+	CounterAccountName     *string `json:"counterAccountName"`
+	CounterAccountNumber   *string `json:"counterAccountNumber"`
+	VirtualAccountName     *string `json:"virtualAccountName"`
+	VirtualAccountNumber   *string `json:"virtualAccountNumber"`
+}
+
+// webhook mirrors a payment webhook payload
+type webhook struct {
+	Code      string      `json:"code"`
+	Desc      string      `json:"desc"`
+	Success   bool        `json:"success"`
+	Data      webhookData `json:"data"`
+	Signature string      `json:"signature"`
+}
+
+// confirmWebhook handles POST /confirm-webhook
+func (s *Server) confirmWebhook(w http.ResponseWriter, body []byte) {
+	var req struct {
+		WebhookUrl string `json:"webhookUrl"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil || req.WebhookUrl == "" {
+		s.writeError(w, http.StatusBadRequest, codeInvalidParams, "webhookUrl is required")
+		return
+	}
+
+	s.mu.Lock()
+	s.webhookURL = req.WebhookUrl
+	s.mu.Unlock()
+
+	s.writeUnsigned(w, map[string]interface{}{
+		"webhookUrl":    req.WebhookUrl,
+		"accountName":   s.accountName,
+		"accountNumber": s.accountNumber,
+		"name":          s.accountName,
+		"shortName":     "PAYOSTEST",
+	})
+}
+
+// MarkPaid marks a pending payment link as PAID by payment link ID or order code
+// It records a transaction for the full remaining amount and issues an invoice
+func (s *Server) MarkPaid(id interface{}) error {
+	idStr, err := formatId(id)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	link := s.findPaymentLink(idStr)
+	if link == nil {
+		return fmt.Errorf("payostest: payment link %s not found", idStr)
+	}
+	if link.data.Status != statusPending {
+		return fmt.Errorf("payostest: payment link %s is %s", idStr, link.data.Status)
+	}
+
+	paidAt := time.Now().Format("2006-01-02 15:04:05")
+	link.data.Transactions = append(link.data.Transactions, transaction{
+		Reference:           newId()[:12],
+		Amount:              link.data.AmountRemaining,
+		AccountNumber:       s.accountNumber,
+		Description:         link.description,
+		TransactionDateTime: paidAt,
+	})
+	link.data.AmountPaid += link.data.AmountRemaining
+	link.data.AmountRemaining = 0
+	link.data.Status = statusPaid
+
+	issuedTimestamp := time.Now().Unix()
+	link.invoices = append(link.invoices, invoice{
+		InvoiceId:       newId(),
+		IssuedTimestamp: &issuedTimestamp,
+		IssuedDatetime:  &paidAt,
+	})
+	return nil
+}
+
+// MarkExpired marks a pending payment link as EXPIRED by payment link ID or order code
+func (s *Server) MarkExpired(id interface{}) error {
+	idStr, err := formatId(id)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	link := s.findPaymentLink(idStr)
+	if link == nil {
+		return fmt.Errorf("payostest: payment link %s not found", idStr)
+	}
+	if link.data.Status != statusPending {
+		return fmt.Errorf("payostest: payment link %s is %s", idStr, link.data.Status)
+	}
+	link.data.Status = statusExpired
+	return nil
+}
+
+// WebhookPayload builds the signed webhook body payOS sends for the latest transaction of a paid link
+func (s *Server) WebhookPayload(id interface{}) ([]byte, error) {
+	idStr, err := formatId(id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	link := s.findPaymentLink(idStr)
+	if link == nil {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("payostest: payment link %s not found", idStr)
+	}
+	if len(link.data.Transactions) == 0 {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("payostest: payment link %s has no transaction", idStr)
+	}
+	tx := link.data.Transactions[len(link.data.Transactions)-1]
+	data := webhookData{
+		OrderCode:              link.data.OrderCode,
+		Amount:                 tx.Amount,
+		Description:            tx.Description,
+		AccountNumber:          tx.AccountNumber,
+		Reference:              tx.Reference,
+		TransactionDateTime:    tx.TransactionDateTime,
+		Currency:               "VND",
+		PaymentLinkId:          link.data.Id,
+		Code:                   codeSuccess,
+		Desc:                   "success",
+		CounterAccountBankId:   tx.CounterAccountBankId,
+		CounterAccountBankName: tx.CounterAccountBankName,
+		CounterAccountName:     tx.CounterAccountName,
+		CounterAccountNumber:   tx.CounterAccountNumber,
+		VirtualAccountName:     tx.VirtualAccountName,
+		VirtualAccountNumber:   tx.VirtualAccountNumber,
+	}
+	s.mu.Unlock()
+
+	sig, err := signature.Sign(signature.SchemeBody, s.ChecksumKey, data)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(webhook{
+		Code:      codeSuccess,
+		Desc:      "success",
+		Success:   true,
+		Data:      data,
+		Signature: sig,
+	})
+}
+
+// SendWebhook posts the signed webhook of a paid link to the URL registered through /confirm-webhook
+// It returns the status code answered by the webhook receiver
+func (s *Server) SendWebhook(ctx context.Context, id interface{}) (int, error) {
+	webhookURL := s.WebhookURL()
+	if webhookURL == "" {
+		return 0, fmt.Errorf("payostest: no webhook URL registered")
+	}
+
+	payload, err := s.WebhookPayload(id)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode, nil
+}
@@ -5,10 +5,8 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/payOSHQ/payos-lib-golang/v2/internal/apierror"
-	"github.com/payOSHQ/payos-lib-golang/v2/internal/apijson"
-	"github.com/payOSHQ/payos-lib-golang/v2/internal/crypto"
-	"github.com/payOSHQ/payos-lib-golang/v2/internal/pagination"
+	"github.com/payOSHQ/payos-lib-golang/v2/apierror"
+	"github.com/payOSHQ/payos-lib-golang/v2/pagination"
 )
 
 // ========================
@@ -125,6 +123,13 @@ type GetPayoutListParams struct {
 	Offset        *int                 `json:"offset,omitempty"`
 }
 
+// WithOffset returns a copy of the params starting at offset
+func (params *GetPayoutListParams) WithOffset(offset int) pagination.Params {
+	next := *params
+	next.Offset = &offset
+	return &next
+}
+
 // newPayouts creates a new Payouts instance
 func newPayouts(client *Client) *Payouts {
 	p := &Payouts{
@@ -135,70 +140,57 @@ func newPayouts(client *Client) *Payouts {
 }
 
 // Create creates a new payout
-func (p *Payouts) Create(ctx context.Context, payoutData PayoutRequest, idempotencyKey *string) (*Payout, error) {
+func (p *Payouts) Create(ctx context.Context, payoutData PayoutRequest, idempotencyKey *string, opts ...RequestOption) (*Payout, error) {
+	opts = withOperation("Payouts.Create", opts)
 	// Generate idempotency key if not provided
-	key := ""
-	if idempotencyKey != nil {
-		key = *idempotencyKey
-	} else {
-		key = crypto.GenerateUUID()
-	}
-
-	// Add idempotency key to request options through Request method
-	result, err := p.client.Post(ctx, "/v1/payouts/", payoutData, &SignatureOpts{
-		Request:  "header",
-		Response: "header",
-	}, map[string]string{"x-idempotency-key": key})
+	key, err := p.client.resolveIdempotencyKey(ctx, idempotencyKey, payoutData, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	var response Payout
-	if err := apijson.ConvertInterface(result, &response); err != nil {
-		return nil, apierror.NewPayOSError("failed to parse response")
-	}
-
-	return &response, nil
+	// Add idempotency key to request options through Request method
+	return Do[Payout](ctx, p.client, &RequestOptions{
+		Method: "POST",
+		Path:   "/v1/payouts/",
+		Body:   payoutData,
+		SignatureOpts: &SignatureOpts{
+			Request:  "header",
+			Response: "header",
+		},
+		Headers: map[string]string{"x-idempotency-key": key},
+	}, opts...)
 }
 
 // Get retrieves detailed information about a specific payout
-func (p *Payouts) Get(ctx context.Context, payoutId string) (*Payout, error) {
+func (p *Payouts) Get(ctx context.Context, payoutId string, opts ...RequestOption) (*Payout, error) {
+	opts = withOperation("Payouts.Get", opts)
 	if payoutId == "" {
 		return nil, apierror.NewPayOSError("invalid params")
 	}
 
 	path := fmt.Sprintf("/v1/payouts/%s", payoutId)
-	result, err := p.client.Get(ctx, path, nil, nil, &SignatureOpts{Response: "header"})
-	if err != nil {
-		return nil, err
-	}
-
-	var response Payout
-	if err := apijson.ConvertInterface(result, &response); err != nil {
-		return nil, apierror.NewPayOSError("failed to parse response")
-	}
-
-	return &response, nil
+	return Do[Payout](ctx, p.client, &RequestOptions{
+		Method:        "GET",
+		Path:          path,
+		SignatureOpts: &SignatureOpts{Response: "header"},
+	}, opts...)
 }
 
 // EstimateCredit estimates credit required for one or multiple payouts
-func (p *Payouts) EstimateCredit(ctx context.Context, payoutData interface{}) (*EstimateCredit, error) {
-	result, err := p.client.Post(ctx, "/v1/payouts/estimate-credit", payoutData, &SignatureOpts{Request: "header"}, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	var response EstimateCredit
-	if err := apijson.ConvertInterface(result, &response); err != nil {
-		return nil, apierror.NewPayOSError("failed to parse response")
-	}
-
-	return &response, nil
+func (p *Payouts) EstimateCredit(ctx context.Context, payoutData interface{}, opts ...RequestOption) (*EstimateCredit, error) {
+	opts = withOperation("Payouts.EstimateCredit", opts)
+	return Do[EstimateCredit](ctx, p.client, &RequestOptions{
+		Method:        "POST",
+		Path:          "/v1/payouts/estimate-credit",
+		Body:          payoutData,
+		SignatureOpts: &SignatureOpts{Request: "header"},
+	}, opts...)
 }
 
 // List retrieves a paginated list of payouts filtered by the given criteria
 // Returns a Page object that supports manual pagination with GetNextPage()
-func (p *Payouts) List(ctx context.Context, params *GetPayoutListParams) (*pagination.Page[Payout], error) {
+func (p *Payouts) List(ctx context.Context, params *GetPayoutListParams, opts ...RequestOption) (*pagination.Page[Payout], error) {
+	opts = withOperation("Payouts.List", opts)
 	if params == nil {
 		params = &GetPayoutListParams{
 			Limit:  intPtr(10),
@@ -206,7 +198,7 @@ func (p *Payouts) List(ctx context.Context, params *GetPayoutListParams) (*pagin
 		}
 	}
 
-	page, err := p.fetchPayoutPage(ctx, params)
+	page, err := p.fetchPayoutPage(ctx, params, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -215,7 +207,8 @@ func (p *Payouts) List(ctx context.Context, params *GetPayoutListParams) (*pagin
 }
 
 // ListAutoPaging returns an iterator that automatically fetches all pages
-func (p *Payouts) ListAutoPaging(ctx context.Context, params *GetPayoutListParams) *pagination.PageIterator[Payout] {
+func (p *Payouts) ListAutoPaging(ctx context.Context, params *GetPayoutListParams, opts ...RequestOption) *pagination.PageIterator[Payout] {
+	opts = withOperation("Payouts.List", opts)
 	if params == nil {
 		params = &GetPayoutListParams{
 			Limit:  intPtr(20),
@@ -223,15 +216,11 @@ func (p *Payouts) ListAutoPaging(ctx context.Context, params *GetPayoutListParam
 		}
 	}
 
-	fetcher := func(ctx context.Context, params interface{}) (*pagination.Page[Payout], error) {
-		return p.fetchPayoutPage(ctx, params.(*GetPayoutListParams))
-	}
-
-	return pagination.NewPageIterator(ctx, params, fetcher)
+	return pagination.NewPageIterator[Payout](ctx, params, p.payoutFetcher(opts))
 }
 
 // fetchPayoutPage is the internal method to fetch a single page of payouts
-func (p *Payouts) fetchPayoutPage(ctx context.Context, params *GetPayoutListParams) (*pagination.Page[Payout], error) {
+func (p *Payouts) fetchPayoutPage(ctx context.Context, params *GetPayoutListParams, opts []RequestOption) (*pagination.Page[Payout], error) {
 	// Build query parameters
 	query := make(map[string]interface{})
 	if params.ReferenceId != nil {
@@ -256,32 +245,36 @@ func (p *Payouts) fetchPayoutPage(ctx context.Context, params *GetPayoutListPara
 		query["offset"] = *params.Offset
 	}
 
-	result, err := p.client.Get(ctx, "/v1/payouts", query, nil, &SignatureOpts{Response: "header"})
+	response, err := Do[PayoutListResponse](ctx, p.client, &RequestOptions{
+		Method:        "GET",
+		Path:          "/v1/payouts",
+		Query:         query,
+		SignatureOpts: &SignatureOpts{Response: "header"},
+	}, opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	var response PayoutListResponse
-	if err := apijson.ConvertInterface(result, &response); err != nil {
-		return nil, apierror.NewPayOSError("failed to parse response")
-	}
-
 	// Create Page object
-	fetcher := func(ctx context.Context, params interface{}) (*pagination.Page[Payout], error) {
-		return p.fetchPayoutPage(ctx, params.(*GetPayoutListParams))
-	}
-
 	page := &pagination.Page[Payout]{
 		Data:       response.Payouts,
 		Pagination: response.Pagination,
 		Ctx:        ctx,
 		Params:     params,
-		Fetcher:    fetcher,
+		Fetcher:    p.payoutFetcher(opts),
 	}
 
 	return page, nil
 }
 
+// payoutFetcher adapts fetchPayoutPage to pagination.Fetcher
+// The request options are reused for every following page
+func (p *Payouts) payoutFetcher(opts []RequestOption) pagination.Fetcher[Payout] {
+	return func(ctx context.Context, params pagination.Params) (*pagination.Page[Payout], error) {
+		return p.fetchPayoutPage(ctx, params.(*GetPayoutListParams), opts)
+	}
+}
+
 // Helper functions
 func intPtr(i int) *int {
 	return &i
@@ -2,9 +2,6 @@ package payos
 
 import (
 	"context"
-
-	"github.com/payOSHQ/payos-lib-golang/v2/internal/apierror"
-	"github.com/payOSHQ/payos-lib-golang/v2/internal/apijson"
 )
 
 // ========================
@@ -32,16 +29,11 @@ func newPayoutsAccount(client *Client) *PayoutsAccount {
 }
 
 // Balance retrieves the current payout account balance
-func (pa *PayoutsAccount) Balance(ctx context.Context) (*PayoutAccountInfo, error) {
-	result, err := pa.client.Get(ctx, "/v1/payouts-account/balance", nil, nil, &SignatureOpts{Response: "header"})
-	if err != nil {
-		return nil, err
-	}
-
-	var response PayoutAccountInfo
-	if err := apijson.ConvertInterface(result, &response); err != nil {
-		return nil, apierror.NewPayOSError("failed to parse response")
-	}
-
-	return &response, nil
+func (pa *PayoutsAccount) Balance(ctx context.Context, opts ...RequestOption) (*PayoutAccountInfo, error) {
+	opts = withOperation("PayoutsAccount.Balance", opts)
+	return Do[PayoutAccountInfo](ctx, pa.client, &RequestOptions{
+		Method:        "GET",
+		Path:          "/v1/payouts-account/balance",
+		SignatureOpts: &SignatureOpts{Response: "header"},
+	}, opts...)
 }
@@ -1,28 +1,131 @@
 package payos
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/payOSHQ/payos-lib-golang/v2/apierror"
+	"github.com/payOSHQ/payos-lib-golang/v2/payostest"
+)
 
 func TestPayouts(t *testing.T) {
 	// TODO: implement test
 	t.Skip("Test implementation pending")
 }
 
+func testPayoutRequest(referenceId string) PayoutRequest {
+	return PayoutRequest{
+		ReferenceId:     referenceId,
+		Amount:          50000,
+		Description:     "payout",
+		ToBin:           "970422",
+		ToAccountNumber: "0123456789",
+		Category:        []string{"test"},
+	}
+}
+
 func TestCreatePayout(t *testing.T) {
-	// TODO: implement test
-	t.Skip("Test implementation pending")
+	client, _ := newTestPayOS(t)
+	ctx := context.Background()
+
+	key := "payout-key-1"
+	first, err := client.Payouts.Create(ctx, testPayoutRequest("ref-1"), &key)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	second, err := client.Payouts.Create(ctx, testPayoutRequest("ref-1"), &key)
+	if err != nil {
+		t.Fatalf("Create() replay error = %v", err)
+	}
+	if first.Id != second.Id {
+		t.Errorf("Create() replay id = %s, want %s", second.Id, first.Id)
+	}
 }
 
 func TestGetPayoutDetails(t *testing.T) {
-	// TODO: implement test
-	t.Skip("Test implementation pending")
+	client, _ := newTestPayOS(t)
+	ctx := context.Background()
+
+	created, err := client.Payouts.Create(ctx, testPayoutRequest("ref-2"), nil)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	payout, err := client.Payouts.Get(ctx, created.Id)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if payout.ReferenceId != "ref-2" || len(payout.Transactions) != 1 {
+		t.Errorf("Get() = %+v", payout)
+	}
 }
 
 func TestListPayouts(t *testing.T) {
-	// TODO: implement test
-	t.Skip("Test implementation pending")
+	client, _ := newTestPayOS(t)
+	ctx := context.Background()
+
+	for _, ref := range []string{"ref-a", "ref-b", "ref-c"} {
+		if _, err := client.Payouts.Create(ctx, testPayoutRequest(ref), nil); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	limit := 2
+	iter := client.Payouts.ListAutoPaging(ctx, &GetPayoutListParams{Limit: &limit})
+	count := 0
+	for iter.Next() {
+		count++
+	}
+	if err := iter.Err(); err != nil {
+		t.Fatalf("ListAutoPaging() error = %v", err)
+	}
+	if count != 3 {
+		t.Errorf("ListAutoPaging() count = %d, want 3", count)
+	}
 }
 
 func TestVerifyPayoutWebhookData(t *testing.T) {
 	// TODO: implement test
 	t.Skip("Test implementation pending")
 }
+
+func TestPayoutErrorCodes(t *testing.T) {
+	client, _ := newTestPayOS(t)
+	ctx := context.Background()
+
+	invalid := testPayoutRequest("ref-invalid")
+	invalid.ToAccountNumber = "not-a-number"
+	_, err := client.Payouts.Create(ctx, invalid, nil)
+	var apiErr *apierror.APIError
+	if !errors.Is(err, apierror.ErrBadRequest) || !errors.Is(err, apierror.ErrInvalidParams) || !errors.As(err, &apiErr) {
+		t.Errorf("Create() invalid account error = %v, want a bad request with invalid params", err)
+	}
+
+	if _, err := client.Payouts.Get(ctx, "missing"); !errors.Is(err, apierror.ErrNotFound) {
+		t.Errorf("Get() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestPayoutInsufficientBalance(t *testing.T) {
+	srv := payostest.NewServer(&payostest.Options{PayoutBalance: 1000})
+	t.Cleanup(srv.Close)
+	client, err := NewPayOS(&PayOSOptions{
+		ClientId:    srv.ClientId,
+		ApiKey:      srv.ApiKey,
+		ChecksumKey: srv.ChecksumKey,
+		BaseURL:     srv.URL,
+	})
+	if err != nil {
+		t.Fatalf("NewPayOS() error = %v", err)
+	}
+
+	_, err = client.Payouts.Create(context.Background(), testPayoutRequest("ref-balance"), nil)
+	var apiErr *apierror.APIError
+	if !errors.Is(err, apierror.ErrBadRequest) || !errors.Is(err, apierror.ErrInvalidParams) || !errors.As(err, &apiErr) {
+		t.Fatalf("Create() error = %v, want a bad request with invalid params", err)
+	}
+	// The reason is only carried by the message returned by payOS
+	if apiErr.Message != "insufficient balance" {
+		t.Errorf("Message = %q", apiErr.Message)
+	}
+}
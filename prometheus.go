@@ -0,0 +1,219 @@
+package payos
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultPrometheusBuckets are the latency histogram buckets in seconds of NewPrometheusMetrics
+var DefaultPrometheusBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// PrometheusMetrics is a Metrics that serves the Prometheus text exposition format
+// It has no dependencies and is served as an http.Handler:
+//
+//	payos_requests_total{operation,method,status,code}
+//	payos_request_duration_seconds{operation}
+//	payos_attempts_total{operation,method,status,code}
+//	payos_attempt_duration_seconds{operation}
+//	payos_retries_total{operation}
+//	payos_signature_failures_total{operation}
+//	payos_webhooks_total{result}
+//	payos_webhook_duration_seconds{result}
+//
+// Calls made without an operation name are labeled with their endpoint group
+type PrometheusMetrics struct {
+	buckets []float64
+
+	mu       sync.Mutex
+	families map[string]*metricFamily
+}
+
+// NewPrometheusMetrics returns a PrometheusMetrics for PayOSOptions.Metrics
+// Buckets are the upper bounds in seconds of the latency histograms, defaulting to DefaultPrometheusBuckets
+func NewPrometheusMetrics(buckets ...float64) *PrometheusMetrics {
+	if len(buckets) == 0 {
+		buckets = DefaultPrometheusBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &PrometheusMetrics{buckets: buckets, families: make(map[string]*metricFamily)}
+}
+
+// ObserveAttempt implements Metrics
+func (p *PrometheusMetrics) ObserveAttempt(ctx context.Context, m AttemptMetrics) {
+	op := metricOperation(m.Operation, m.Path)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.counter("payos_attempts_total", "HTTP attempts sent to payOS, including retries.",
+		[]string{"operation", "method", "status", "code"}, op, m.Method, strconv.Itoa(m.StatusCode), m.Code).add(1)
+	p.histogram("payos_attempt_duration_seconds", "Latency of HTTP attempts to payOS.",
+		[]string{"operation"}, op).observe(m.Latency.Seconds(), p.buckets)
+	if m.SignatureFailure {
+		p.counter("payos_signature_failures_total", "payOS responses whose signature did not verify.",
+			[]string{"operation"}, op).add(1)
+	}
+}
+
+// ObserveRequest implements Metrics
+func (p *PrometheusMetrics) ObserveRequest(ctx context.Context, m RequestMetrics) {
+	op := metricOperation(m.Operation, m.Path)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.counter("payos_requests_total", "payOS API calls by final outcome.",
+		[]string{"operation", "method", "status", "code"}, op, m.Method, strconv.Itoa(m.StatusCode), m.Code).add(1)
+	p.histogram("payos_request_duration_seconds", "Latency of payOS API calls, including retries.",
+		[]string{"operation"}, op).observe(m.Latency.Seconds(), p.buckets)
+	if m.Retries > 0 {
+		p.counter("payos_retries_total", "Retried attempts of payOS API calls.",
+			[]string{"operation"}, op).add(float64(m.Retries))
+	}
+}
+
+// ObserveWebhook implements Metrics
+func (p *PrometheusMetrics) ObserveWebhook(ctx context.Context, m WebhookMetrics) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.counter("payos_webhooks_total", "payOS webhook verifications by result.",
+		[]string{"result"}, m.Result).add(1)
+	p.histogram("payos_webhook_duration_seconds", "Latency of payOS webhook verifications.",
+		[]string{"result"}, m.Result).observe(m.Latency.Seconds(), p.buckets)
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format
+func (p *PrometheusMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	p.WriteTo(w)
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format
+func (p *PrometheusMetrics) WriteTo(w io.Writer) (int64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var b strings.Builder
+	names := make([]string, 0, len(p.families))
+	for name := range p.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p.families[name].write(&b, name, p.buckets)
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// counter returns the counter series of a family, creating both on first use
+func (p *PrometheusMetrics) counter(name, help string, labels []string, values ...string) *metricSeries {
+	return p.family(name, help, "counter", labels).withLabels(values)
+}
+
+// histogram returns the histogram series of a family, creating both on first use
+func (p *PrometheusMetrics) histogram(name, help string, labels []string, values ...string) *metricSeries {
+	return p.family(name, help, "histogram", labels).withLabels(values)
+}
+
+func (p *PrometheusMetrics) family(name, help, kind string, labels []string) *metricFamily {
+	f, ok := p.families[name]
+	if !ok {
+		f = &metricFamily{help: help, kind: kind, labels: labels, series: make(map[string]*metricSeries)}
+		p.families[name] = f
+	}
+	return f
+}
+
+// metricFamily is a metric name with its series by label values
+type metricFamily struct {
+	help   string
+	kind   string
+	labels []string
+	series map[string]*metricSeries
+}
+
+// metricSeries is a counter value, or the bucket counts, sum and count of a histogram
+type metricSeries struct {
+	labels  string
+	value   float64
+	buckets []uint64
+	sum     float64
+	count   uint64
+}
+
+func (f *metricFamily) withLabels(values []string) *metricSeries {
+	pairs := make([]string, len(f.labels))
+	for i, label := range f.labels {
+		pairs[i] = label + `="` + labelValueEscaper.Replace(values[i]) + `"`
+	}
+	key := strings.Join(pairs, ",")
+	s, ok := f.series[key]
+	if !ok {
+		s = &metricSeries{labels: key}
+		f.series[key] = s
+	}
+	return s
+}
+
+func (s *metricSeries) add(v float64) {
+	s.value += v
+}
+
+func (s *metricSeries) observe(v float64, buckets []float64) {
+	if s.buckets == nil {
+		s.buckets = make([]uint64, len(buckets))
+	}
+	for i, upper := range buckets {
+		if v <= upper {
+			s.buckets[i]++
+		}
+	}
+	s.sum += v
+	s.count++
+}
+
+func (f *metricFamily) write(b *strings.Builder, name string, buckets []float64) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, f.help, name, f.kind)
+
+	keys := make([]string, 0, len(f.series))
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		s := f.series[key]
+		if f.kind == "counter" {
+			fmt.Fprintf(b, "%s{%s} %s\n", name, s.labels, formatMetricValue(s.value))
+			continue
+		}
+		for i, upper := range buckets {
+			fmt.Fprintf(b, "%s_bucket{%s,le=\"%s\"} %d\n", name, s.labels, formatMetricValue(upper), s.buckets[i])
+		}
+		fmt.Fprintf(b, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, s.labels, s.count)
+		fmt.Fprintf(b, "%s_sum{%s} %s\n", name, s.labels, formatMetricValue(s.sum))
+		fmt.Fprintf(b, "%s_count{%s} %d\n", name, s.labels, s.count)
+	}
+}
+
+// metricOperation returns the operation label of a call
+func metricOperation(operation, path string) string {
+	if operation != "" {
+		return operation
+	}
+	return EndpointGroup(path)
+}
+
+// labelValueEscaper escapes label values as required by the text exposition format
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatMetricValue(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,344 @@
+package payos
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Endpoint groups used by the rate limiter
+const (
+	EndpointGroupPaymentRequests = "payment-requests"
+	EndpointGroupPayouts         = "payouts"
+	EndpointGroupOther           = "other"
+)
+
+// EndpointGroup returns the endpoint group of a request path
+// Invoices belong to the payment requests group and the payouts account to the payouts group
+func EndpointGroup(path string) string {
+	switch {
+	case strings.HasPrefix(path, "/v2/payment-requests"):
+		return EndpointGroupPaymentRequests
+	case strings.HasPrefix(path, "/v1/payouts"):
+		return EndpointGroupPayouts
+	default:
+		return EndpointGroupOther
+	}
+}
+
+// RateLimit configures the limiter of an endpoint group
+type RateLimit struct {
+	// RequestsPerSecond is the sustained request rate
+	// Zero disables the token bucket
+	RequestsPerSecond float64
+
+	// Burst is the number of requests that can be sent at once
+	// Defaults to RequestsPerSecond rounded up
+	Burst int
+
+	// MaxConcurrency is the initial and maximum number of requests in flight
+	// Zero disables the concurrency limit
+	MaxConcurrency int
+
+	// MinConcurrency is the floor of the adaptive concurrency limit
+	// Defaults to 1
+	MinConcurrency int
+}
+
+// RateLimiterOptions configures a RateLimiter
+type RateLimiterOptions struct {
+	// Limits holds the limits by endpoint group, see EndpointGroup
+	Limits map[string]RateLimit
+
+	// Default applies to groups missing from Limits
+	Default RateLimit
+
+	// Clock refills the token buckets and waits for tokens
+	// Defaults to SystemClock
+	Clock Clock
+}
+
+// RateLimiterStats is a snapshot of the limiter of an endpoint group
+type RateLimiterStats struct {
+	// Tokens is the number of requests that can be sent without waiting
+	Tokens float64
+
+	// ConcurrencyLimit is the current adaptive concurrency limit, zero when disabled
+	ConcurrencyLimit float64
+
+	// InFlight is the number of requests being sent
+	InFlight int
+
+	// Waiting is the number of requests blocked by the limiter
+	Waiting int
+
+	// Requests is the number of requests let through
+	Requests uint64
+
+	// Throttled is the number of 429 responses and responses with X-RateLimit-Remaining at zero
+	Throttled uint64
+
+	// WaitTime is the total time requests spent blocked by the limiter
+	WaitTime time.Duration
+}
+
+// RateLimiter throttles requests before they are sent, per endpoint group
+// Each group has a token bucket and an AIMD concurrency limit: the limit grows by one
+// per window of successful responses and is halved on 429 responses or when
+// X-RateLimit-Remaining reaches zero, in which case the bucket also pauses until X-RateLimit-Reset
+type RateLimiter struct {
+	opts RateLimiterOptions
+
+	mu     sync.Mutex
+	groups map[string]*groupLimiter
+}
+
+// NewRateLimiter returns a RateLimiter for PayOSOptions.RateLimiter
+func NewRateLimiter(opts RateLimiterOptions) *RateLimiter {
+	opts.Clock = orSystemClock(opts.Clock)
+	return &RateLimiter{opts: opts, groups: make(map[string]*groupLimiter)}
+}
+
+// Middleware returns the limiter as a Middleware
+// It blocks each attempt until the limits of its endpoint group allow it, or until the context is done
+func (l *RateLimiter) Middleware() Middleware {
+	return func(next RequestHandler) RequestHandler {
+		return func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			g := l.group(EndpointGroup(req.URL.Path))
+			if err := g.acquire(ctx); err != nil {
+				return nil, err
+			}
+			resp, err := next(ctx, req)
+			g.release(resp, err)
+			return resp, err
+		}
+	}
+}
+
+// Stats returns a snapshot of every endpoint group used so far
+func (l *RateLimiter) Stats() map[string]RateLimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	stats := make(map[string]RateLimiterStats, len(l.groups))
+	for name, g := range l.groups {
+		stats[name] = g.stats()
+	}
+	return stats
+}
+
+// group returns the limiter of an endpoint group, creating it on first use
+func (l *RateLimiter) group(name string) *groupLimiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	g, ok := l.groups[name]
+	if !ok {
+		limit, ok := l.opts.Limits[name]
+		if !ok {
+			limit = l.opts.Default
+		}
+		g = newGroupLimiter(limit, l.opts.Clock)
+		l.groups[name] = g
+	}
+	return g
+}
+
+// groupLimiter is the token bucket and concurrency limit of an endpoint group
+type groupLimiter struct {
+	rate           float64
+	burst          float64
+	maxConcurrency float64
+	minConcurrency float64
+	clock          Clock
+
+	mu        sync.Mutex
+	tokens    float64
+	last      time.Time
+	pauseTill time.Time
+	limit     float64
+	inFlight  int
+	waiting   int
+	changed   chan struct{}
+	requests  uint64
+	throttled uint64
+	waitTime  time.Duration
+}
+
+func newGroupLimiter(limit RateLimit, clock Clock) *groupLimiter {
+	burst := float64(limit.Burst)
+	if burst <= 0 {
+		burst = math.Max(math.Ceil(limit.RequestsPerSecond), 1)
+	}
+	minConcurrency := float64(limit.MinConcurrency)
+	if minConcurrency < 1 {
+		minConcurrency = 1
+	}
+	return &groupLimiter{
+		rate:           limit.RequestsPerSecond,
+		burst:          burst,
+		maxConcurrency: float64(limit.MaxConcurrency),
+		minConcurrency: minConcurrency,
+		clock:          clock,
+		tokens:         burst,
+		last:           clock.Now(),
+		limit:          float64(limit.MaxConcurrency),
+		changed:        make(chan struct{}),
+	}
+}
+
+// acquire waits for a concurrency slot, then for a token
+func (g *groupLimiter) acquire(ctx context.Context) error {
+	start := g.clock.Now()
+	g.mu.Lock()
+	g.waiting++
+	defer func() {
+		g.waiting--
+		g.waitTime += g.clock.Now().Sub(start)
+		g.mu.Unlock()
+	}()
+
+	for g.maxConcurrency > 0 && float64(g.inFlight) >= math.Floor(g.limit) {
+		changed := g.changed
+		g.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			g.mu.Lock()
+			return ctx.Err()
+		case <-changed:
+		}
+		g.mu.Lock()
+	}
+	g.inFlight++
+
+	now := g.clock.Now()
+	if delay := g.reserve(now); delay > 0 {
+		// Context deadlines are wall-clock times, whatever the limiter clock tells
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			g.cancelReservation()
+			return &rateLimitDeadlineError{delay: delay}
+		}
+		g.mu.Unlock()
+		err := sleepContext(ctx, g.clock, delay)
+		g.mu.Lock()
+		if err != nil {
+			g.cancelReservation()
+			return err
+		}
+	}
+	g.requests++
+	return nil
+}
+
+// rateLimitDeadlineError reports a limiter wait that cannot end before the context deadline
+// Retrying would wait for the same token, so it is never retried
+type rateLimitDeadlineError struct {
+	delay time.Duration
+}
+
+func (e *rateLimitDeadlineError) Error() string {
+	return fmt.Sprintf("payos: rate limiter wait of %v exceeds the context deadline", e.delay)
+}
+
+func (e *rateLimitDeadlineError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// reserve takes a token and returns how long to wait until it is available
+func (g *groupLimiter) reserve(now time.Time) time.Duration {
+	if g.rate <= 0 {
+		if g.pauseTill.After(now) {
+			return g.pauseTill.Sub(now)
+		}
+		return 0
+	}
+
+	g.tokens = math.Min(g.tokens+now.Sub(g.last).Seconds()*g.rate, g.burst)
+	g.last = now
+	g.tokens--
+
+	var delay time.Duration
+	if g.tokens < 0 {
+		delay = time.Duration(-g.tokens / g.rate * float64(time.Second))
+	}
+	if pause := g.pauseTill.Sub(now); pause > delay {
+		delay = pause
+	}
+	return delay
+}
+
+// cancelReservation returns the token and slot of a request that was not sent
+func (g *groupLimiter) cancelReservation() {
+	if g.rate > 0 {
+		g.tokens++
+	}
+	g.releaseSlot()
+}
+
+// release frees the slot of a sent request and adapts the limits to the response
+func (g *groupLimiter) release(resp *http.Response, err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.releaseSlot()
+	if err != nil || resp == nil {
+		return
+	}
+
+	remaining, hasRemaining := parseHeaderFloat(resp.Header, "X-RateLimit-Remaining")
+	exhausted := hasRemaining && remaining <= 0
+	if resp.StatusCode == http.StatusTooManyRequests || exhausted {
+		g.throttled++
+		if g.maxConcurrency > 0 {
+			g.limit = math.Max(g.limit/2, g.minConcurrency)
+		}
+		now := g.clock.Now()
+		if wait, ok := serverRetryAfter(resp.Header, now); ok {
+			if until := now.Add(wait); until.After(g.pauseTill) {
+				g.pauseTill = until
+			}
+		}
+		return
+	}
+
+	if g.maxConcurrency > 0 && resp.StatusCode < 500 {
+		g.limit = math.Min(g.limit+1/g.limit, g.maxConcurrency)
+	}
+}
+
+// releaseSlot frees a concurrency slot and wakes the waiting requests
+func (g *groupLimiter) releaseSlot() {
+	g.inFlight--
+	close(g.changed)
+	g.changed = make(chan struct{})
+}
+
+func (g *groupLimiter) stats() RateLimiterStats {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	tokens := g.tokens
+	if g.rate > 0 {
+		tokens = math.Min(tokens+g.clock.Now().Sub(g.last).Seconds()*g.rate, g.burst)
+	}
+	return RateLimiterStats{
+		Tokens:           tokens,
+		ConcurrencyLimit: g.limit,
+		InFlight:         g.inFlight,
+		Waiting:          g.waiting,
+		Requests:         g.requests,
+		Throttled:        g.throttled,
+		WaitTime:         g.waitTime,
+	}
+}
+
+// parseHeaderFloat parses a numeric response header
+func parseHeaderFloat(header http.Header, name string) (float64, bool) {
+	value := header.Get(name)
+	if value == "" {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	return f, err == nil
+}
@@ -0,0 +1,183 @@
+package payos
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/payOSHQ/payos-lib-golang/v2/apierror"
+)
+
+func TestEndpointGroup(t *testing.T) {
+	tests := map[string]string{
+		"/v2/payment-requests":              EndpointGroupPaymentRequests,
+		"/v2/payment-requests/123/invoices": EndpointGroupPaymentRequests,
+		"/v1/payouts/batch":                 EndpointGroupPayouts,
+		"/v1/payouts-account/balance":       EndpointGroupPayouts,
+		"/confirm-webhook":                  EndpointGroupOther,
+	}
+	for path, want := range tests {
+		if got := EndpointGroup(path); got != want {
+			t.Errorf("EndpointGroup(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestRateLimiterTokenBucket(t *testing.T) {
+	limiter := NewRateLimiter(RateLimiterOptions{
+		Limits: map[string]RateLimit{EndpointGroupPayouts: {RequestsPerSecond: 20, Burst: 1}},
+	})
+	g := limiter.group(EndpointGroupPayouts)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := g.acquire(ctx); err != nil {
+			t.Fatalf("acquire() error = %v", err)
+		}
+		g.release(nil, nil)
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("3 requests at 20/s took %v, want at least 100ms", elapsed)
+	}
+
+	// Waits that outlast the deadline fail fast and give the token back
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	g.acquire(context.Background())
+	g.release(nil, nil)
+	if err := g.acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("acquire() error = %v, want deadline exceeded", err)
+	}
+	if stats := limiter.Stats()[EndpointGroupPayouts]; stats.Requests != 4 || stats.InFlight != 0 || stats.Tokens >= 1 {
+		t.Errorf("Stats() = %+v", stats)
+	}
+}
+
+func TestRateLimiterAdaptiveConcurrency(t *testing.T) {
+	limiter := NewRateLimiter(RateLimiterOptions{Default: RateLimit{MaxConcurrency: 4}})
+	g := limiter.group(EndpointGroupOther)
+	ctx := context.Background()
+
+	throttled := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+	g.acquire(ctx)
+	g.release(throttled, nil)
+	if got := limiter.Stats()[EndpointGroupOther].ConcurrencyLimit; got != 2 {
+		t.Fatalf("ConcurrencyLimit after 429 = %v, want 2", got)
+	}
+
+	exhausted := &http.Response{StatusCode: http.StatusOK, Header: http.Header{"X-Ratelimit-Remaining": {"0"}}}
+	g.acquire(ctx)
+	g.release(exhausted, nil)
+	g.acquire(ctx)
+	g.release(exhausted, nil)
+	if got := limiter.Stats()[EndpointGroupOther].ConcurrencyLimit; got != 1 {
+		t.Fatalf("ConcurrencyLimit floor = %v, want 1", got)
+	}
+
+	ok := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}
+	g.acquire(ctx)
+	g.release(ok, nil)
+	if got := limiter.Stats()[EndpointGroupOther].ConcurrencyLimit; got != 2 {
+		t.Fatalf("ConcurrencyLimit after success = %v, want 2", got)
+	}
+
+	// With the limit at 2, a third request waits for a slot
+	g.acquire(ctx)
+	g.acquire(ctx)
+	waitCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := g.acquire(waitCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("acquire() error = %v, want deadline exceeded", err)
+	}
+
+	done := make(chan error)
+	go func() { done <- g.acquire(ctx) }()
+	time.Sleep(10 * time.Millisecond)
+	g.release(ok, nil)
+	if err := <-done; err != nil {
+		t.Fatalf("acquire() after release error = %v", err)
+	}
+	if stats := limiter.Stats()[EndpointGroupOther]; stats.InFlight != 2 || stats.Throttled != 3 || stats.WaitTime <= 0 {
+		t.Errorf("Stats() = %+v", stats)
+	}
+}
+
+func TestClientRateLimiter(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	limiter := NewRateLimiter(RateLimiterOptions{Default: RateLimit{RequestsPerSecond: 100, MaxConcurrency: 8}})
+	client, err := NewPayOS(&PayOSOptions{
+		ClientId:    "client-id",
+		ApiKey:      "api-key",
+		ChecksumKey: "checksum-key",
+		BaseURL:     srv.URL,
+		MaxRetries:  2,
+		RateLimiter: limiter,
+	})
+	if err != nil {
+		t.Fatalf("NewPayOS() error = %v", err)
+	}
+
+	if _, err := client.PaymentRequests.Get(context.Background(), 123); err == nil {
+		t.Fatal("Get() expected error")
+	}
+	stats := limiter.Stats()[EndpointGroupPaymentRequests]
+	if stats.Requests != 3 || stats.Throttled != 3 || stats.ConcurrencyLimit != 1 {
+		t.Errorf("Stats() = %+v", stats)
+	}
+}
+
+func TestClientRateLimiterDeadline(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Write([]byte(`{"code":"00","desc":"success","data":{}}`))
+	}))
+	defer srv.Close()
+
+	limiter := NewRateLimiter(RateLimiterOptions{Default: RateLimit{RequestsPerSecond: 1, Burst: 1}})
+	client, err := NewClient(&PayOSOptions{
+		ClientId:    "client-id",
+		ApiKey:      "api-key",
+		ChecksumKey: "checksum-key",
+		BaseURL:     srv.URL,
+		MaxRetries:  2,
+		RateLimiter: limiter,
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	opts := &RequestOptions{Method: http.MethodGet, Path: "/v1/test"}
+	if _, err := client.Request(context.Background(), opts); err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+
+	// The next token is a second away, so the call fails at once without retries
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = client.Request(ctx, opts)
+	if !errors.Is(err, apierror.ErrConnectionTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Request() error = %v, want a connection timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("Request() took %v, want it to fail fast", elapsed)
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("server received %d requests, want 1", n)
+	}
+	if stats := limiter.Stats()[EndpointGroupOther]; stats.Requests != 1 {
+		t.Errorf("Stats() = %+v, want a single request let through", stats)
+	}
+}
@@ -0,0 +1,216 @@
+package payos
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/payOSHQ/payos-lib-golang/v2/apierror"
+)
+
+// RequestOption configures a single API call on top of the PayOSOptions defaults
+type RequestOption func(*requestConfig)
+
+// requestConfig holds the per-call settings resolved from RequestOption values
+type requestConfig struct {
+	baseURL        string
+	maxRetries     int
+	timeout        time.Duration
+	headers        map[string]string
+	idempotencyKey string
+	middlewares    []Middleware
+	responseInto   *http.Response
+	rawResponse    *RawResponse
+	operation      string
+	retryPolicy    RetryPolicy
+
+	// lastStatus and lastCode are those of the last attempt, for metrics
+	lastStatus int
+	lastCode   string
+}
+
+// newRequestConfig applies the request options on top of the client defaults
+func (c *Client) newRequestConfig(opts []RequestOption) *requestConfig {
+	cfg := &requestConfig{
+		baseURL:     c.baseURL,
+		maxRetries:  c.maxRetries,
+		retryPolicy: c.retryPolicy,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(cfg)
+		}
+	}
+	return cfg
+}
+
+// WithMaxRetries sets the maximum number of retry attempts for the call
+// Use 0 to disable retries
+func WithMaxRetries(maxRetries int) RequestOption {
+	return func(cfg *requestConfig) {
+		if maxRetries < 0 {
+			maxRetries = 0
+		}
+		cfg.maxRetries = maxRetries
+	}
+}
+
+// WithRetryPolicy overrides the retry policy of the client for the call
+func WithRetryPolicy(policy RetryPolicy) RequestOption {
+	return func(cfg *requestConfig) {
+		if policy != nil {
+			cfg.retryPolicy = policy
+		}
+	}
+}
+
+// WithTimeout bounds the whole call, including retries and backoff, by the given duration
+func WithTimeout(timeout time.Duration) RequestOption {
+	return func(cfg *requestConfig) {
+		cfg.timeout = timeout
+	}
+}
+
+// WithHeader sets an additional header on the request
+// It overrides any header set by the client for the same key
+func WithHeader(key, value string) RequestOption {
+	return func(cfg *requestConfig) {
+		if cfg.headers == nil {
+			cfg.headers = make(map[string]string)
+		}
+		cfg.headers[key] = value
+	}
+}
+
+// WithIdempotencyKey sets the x-idempotency-key header of the request
+// For payout creation it replaces the generated idempotency key
+func WithIdempotencyKey(key string) RequestOption {
+	return func(cfg *requestConfig) {
+		cfg.idempotencyKey = key
+	}
+}
+
+// WithBaseURL sends the request to a different base URL
+func WithBaseURL(baseURL string) RequestOption {
+	return func(cfg *requestConfig) {
+		cfg.baseURL = baseURL
+	}
+}
+
+// WithMiddleware adds middlewares for the call
+// They run inside the middlewares configured in PayOSOptions
+func WithMiddleware(middlewares ...Middleware) RequestOption {
+	return func(cfg *requestConfig) {
+		cfg.middlewares = append(cfg.middlewares, middlewares...)
+	}
+}
+
+// WithResponseInto copies the HTTP response of the last attempt into dst
+// The body of the copied response can be read again
+func WithResponseInto(dst *http.Response) RequestOption {
+	return func(cfg *requestConfig) {
+		cfg.responseInto = dst
+	}
+}
+
+// WithOperation names the call in errors, such as Payouts.Create
+// Resource methods set it to their own name
+func WithOperation(name string) RequestOption {
+	return func(cfg *requestConfig) {
+		cfg.operation = name
+	}
+}
+
+// withOperation prepends the operation name of a resource method so callers can override it
+func withOperation(name string, opts []RequestOption) []RequestOption {
+	return append([]RequestOption{WithOperation(name)}, opts...)
+}
+
+// WithRawResponse fills dst with the metadata of the call once it returns
+// dst is filled for failed calls too when the server answered
+func WithRawResponse(dst *RawResponse) RequestOption {
+	return func(cfg *requestConfig) {
+		cfg.rawResponse = dst
+	}
+}
+
+// applyHeaders sets the per-call headers on the request headers
+func (cfg *requestConfig) applyHeaders(headers http.Header) {
+	for key, value := range cfg.headers {
+		headers.Set(key, value)
+	}
+	if cfg.idempotencyKey != "" {
+		headers.Set("x-idempotency-key", cfg.idempotencyKey)
+	}
+}
+
+// requestIdempotencyKey returns the x-idempotency-key sent with a request built from headers
+// WithIdempotencyKey wins over WithHeader, which wins over the headers of the request options
+func (cfg *requestConfig) requestIdempotencyKey(headers map[string]string) string {
+	h := make(http.Header, len(headers))
+	for key, value := range headers {
+		h.Set(key, value)
+	}
+	cfg.applyHeaders(h)
+	return h.Get("x-idempotency-key")
+}
+
+// captureResponse copies the response into the WithResponseInto destination
+func (cfg *requestConfig) captureResponse(resp *http.Response, body []byte) {
+	if cfg.responseInto == nil {
+		return
+	}
+	*cfg.responseInto = *resp
+	cfg.responseInto.Body = io.NopCloser(bytes.NewReader(body))
+}
+
+// captureRaw records the response of an attempt into the WithRawResponse destination
+func (cfg *requestConfig) captureRaw(resp *http.Response, body []byte, attempt int) {
+	dst := cfg.rawResponse
+	if dst == nil {
+		return
+	}
+	dst.StatusCode = resp.StatusCode
+	dst.Header = resp.Header.Clone()
+	dst.Body = body
+	dst.Retries = attempt
+	dst.Code, dst.Desc, dst.Data, dst.Signature = "", "", nil, ""
+
+	var apiResp rawResponse
+	if json.Unmarshal(body, &apiResp) == nil {
+		dst.Code = apiResp.Code
+		dst.Desc = apiResp.Desc
+		dst.Data = apiResp.Data
+		if apiResp.Signature != nil {
+			dst.Signature = *apiResp.Signature
+		}
+	}
+	if dst.Signature == "" {
+		dst.Signature = resp.Header.Get("x-signature")
+	}
+}
+
+// captureLatency records the total duration of the call into the WithRawResponse destination
+func (cfg *requestConfig) captureLatency(latency time.Duration) {
+	if cfg.rawResponse != nil {
+		cfg.rawResponse.Latency = latency
+	}
+}
+
+// operationError wraps the final error of a call with its request context and attempts
+func (cfg *requestConfig) operationError(method, path, idempotencyKey string, attempts []apierror.Attempt, err error) error {
+	return apierror.NewOperationError(cfg.operation, method, path, idempotencyKey, attempts, err)
+}
+
+// newAttempt records a failed attempt
+func newAttempt(err error) apierror.Attempt {
+	attempt := apierror.Attempt{Err: err}
+	var apiErr *apierror.APIError
+	if errors.As(err, &apiErr) {
+		attempt.StatusCode = apiErr.StatusCode
+	}
+	return attempt
+}
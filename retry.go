@@ -0,0 +1,313 @@
+package payos
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/payOSHQ/payos-lib-golang/v2/apierror"
+)
+
+// RetryAttempt describes a failed attempt passed to a RetryPolicy
+type RetryAttempt struct {
+	// Operation names the SDK method, such as Payouts.Create
+	Operation string
+
+	Method string
+	Path   string
+
+	// IdempotencyKey is the x-idempotency-key sent with the request, if any
+	IdempotencyKey string
+
+	// Attempt is the zero-based number of the failed attempt
+	Attempt int
+
+	// Err is the error of the attempt
+	Err error
+
+	// Headers are the response headers of the attempt, or nil if no response was received
+	Headers http.Header
+
+	// Remaining is the time left before the context deadline, valid when HasDeadline is true
+	Remaining   time.Duration
+	HasDeadline bool
+
+	// clock and jitter are those of the client, used by DefaultRetryPolicy unless it sets its own
+	clock  Clock
+	jitter JitterSource
+}
+
+// RetryPolicy decides whether a failed attempt is retried and how long to wait before the next one
+// It is only consulted while the call has retries left, see PayOSOptions.MaxRetries
+type RetryPolicy interface {
+	// Retry returns the wait before the next attempt and whether to retry at all
+	Retry(ctx context.Context, attempt *RetryAttempt) (time.Duration, bool)
+}
+
+// RetryPolicyFunc adapts a function to a RetryPolicy
+type RetryPolicyFunc func(ctx context.Context, attempt *RetryAttempt) (time.Duration, bool)
+
+// Retry calls f(ctx, attempt)
+func (f RetryPolicyFunc) Retry(ctx context.Context, attempt *RetryAttempt) (time.Duration, bool) {
+	return f(ctx, attempt)
+}
+
+// RetryObserver is implemented by retry policies that track successful calls, such as RetryBudget
+type RetryObserver interface {
+	// OnSuccess is called when a call succeeds
+	OnSuccess(ctx context.Context)
+}
+
+// Defaults of DefaultRetryPolicy
+const (
+	DefaultInitialBackoff = 500 * time.Millisecond
+	DefaultMaxBackoff     = 10 * time.Second
+	DefaultMaxRetryAfter  = 60 * time.Second
+)
+
+// DefaultRetryPolicy retries connection errors, timeouts, 408, 429 and 5xx responses and retryable payOS codes
+// It waits for Retry-After or X-RateLimit-Reset when present, otherwise it backs off exponentially with jitter
+// Attempts whose wait would outlast the context deadline are not retried
+type DefaultRetryPolicy struct {
+	// InitialBackoff is the wait after the first attempt, doubled after each attempt
+	// Defaults to DefaultInitialBackoff
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the exponential backoff
+	// Defaults to DefaultMaxBackoff
+	MaxBackoff time.Duration
+
+	// MaxRetryAfter is the longest server requested wait that is honored
+	// Longer waits fall back to the exponential backoff
+	// Defaults to DefaultMaxRetryAfter
+	MaxRetryAfter time.Duration
+
+	// Clock resolves Retry-After dates
+	// Defaults to PayOSOptions.Clock
+	Clock Clock
+
+	// Jitter randomizes the exponential backoff
+	// Defaults to PayOSOptions.Jitter
+	Jitter JitterSource
+}
+
+// Retry implements RetryPolicy
+func (p DefaultRetryPolicy) Retry(ctx context.Context, attempt *RetryAttempt) (time.Duration, bool) {
+	if !isRetryableError(attempt.Err) {
+		return 0, false
+	}
+	backoff := p.Backoff(attempt)
+	return backoff, withinDeadline(attempt, backoff)
+}
+
+// Backoff returns the wait before the next attempt
+// A server requested wait that is not positive or longer than MaxRetryAfter is ignored in favor of the exponential backoff
+func (p DefaultRetryPolicy) Backoff(attempt *RetryAttempt) time.Duration {
+	clock := p.Clock
+	if clock == nil {
+		clock = orSystemClock(attempt.clock)
+	}
+	if wait, ok := serverRetryAfter(attempt.Headers, clock.Now()); ok && wait > 0 &&
+		wait <= getDurationValue(p.MaxRetryAfter, DefaultMaxRetryAfter) {
+		return wait
+	}
+
+	initial := getDurationValue(p.InitialBackoff, DefaultInitialBackoff)
+	maxBackoff := getDurationValue(p.MaxBackoff, DefaultMaxBackoff)
+	sleep := math.Min(float64(initial)*math.Pow(2, float64(attempt.Attempt)), float64(maxBackoff))
+	jitter := p.Jitter
+	if jitter == nil {
+		jitter = orGlobalJitter(attempt.jitter)
+	}
+	factor := 1 - jitter.Float64()*0.25 // 75% to 100%
+	return time.Duration(sleep * factor)
+}
+
+// RequireIdempotencyKey returns a policy that never retries POST requests sent without an idempotency key
+// A POST that timed out may have created a payment link or payout, so sending it again could duplicate it
+// Other requests are decided by next, or DefaultRetryPolicy when next is nil
+func RequireIdempotencyKey(next RetryPolicy) RetryPolicy {
+	return &idempotentRetryPolicy{next: orDefaultRetryPolicy(next)}
+}
+
+type idempotentRetryPolicy struct {
+	next RetryPolicy
+}
+
+func (p *idempotentRetryPolicy) Retry(ctx context.Context, attempt *RetryAttempt) (time.Duration, bool) {
+	if attempt.Method == http.MethodPost && attempt.IdempotencyKey == "" {
+		return 0, false
+	}
+	return p.next.Retry(ctx, attempt)
+}
+
+func (p *idempotentRetryPolicy) OnSuccess(ctx context.Context) {
+	observeRetrySuccess(ctx, p.next)
+}
+
+// RetryOnCodes returns a policy that also retries API errors with the given payOS business codes
+// The wait follows DefaultRetryPolicy and the context deadline is respected
+// Other errors are decided by next, or DefaultRetryPolicy when next is nil
+func RetryOnCodes(next RetryPolicy, codes ...string) RetryPolicy {
+	p := &codeRetryPolicy{next: orDefaultRetryPolicy(next), codes: make(map[string]bool, len(codes))}
+	for _, code := range codes {
+		p.codes[code] = true
+	}
+	return p
+}
+
+type codeRetryPolicy struct {
+	next  RetryPolicy
+	codes map[string]bool
+}
+
+func (p *codeRetryPolicy) Retry(ctx context.Context, attempt *RetryAttempt) (time.Duration, bool) {
+	var apiErr *apierror.APIError
+	if errors.As(attempt.Err, &apiErr) && p.codes[apiErr.Code] {
+		backoff := DefaultRetryPolicy{}.Backoff(attempt)
+		return backoff, withinDeadline(attempt, backoff)
+	}
+	return p.next.Retry(ctx, attempt)
+}
+
+func (p *codeRetryPolicy) OnSuccess(ctx context.Context) {
+	observeRetrySuccess(ctx, p.next)
+}
+
+// RetryBudget limits retries across all calls of a client to avoid retry storms during outages
+// Every retry spends a token and every successful call earns TokenRatio tokens, up to MaxTokens
+// Retries are refused while fewer than one token is left
+type RetryBudget struct {
+	next       RetryPolicy
+	maxTokens  float64
+	tokenRatio float64
+
+	mu     sync.Mutex
+	tokens float64
+}
+
+// NewRetryBudget returns a budget of maxTokens retries wrapping next, or DefaultRetryPolicy when next is nil
+// With tokenRatio 0.1, ten successful calls earn one retry
+func NewRetryBudget(next RetryPolicy, maxTokens int, tokenRatio float64) *RetryBudget {
+	return &RetryBudget{
+		next:       orDefaultRetryPolicy(next),
+		maxTokens:  float64(maxTokens),
+		tokenRatio: tokenRatio,
+		tokens:     float64(maxTokens),
+	}
+}
+
+// Retry implements RetryPolicy
+func (b *RetryBudget) Retry(ctx context.Context, attempt *RetryAttempt) (time.Duration, bool) {
+	backoff, ok := b.next.Retry(ctx, attempt)
+	if !ok {
+		return 0, false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens < 1 {
+		return 0, false
+	}
+	b.tokens--
+	return backoff, true
+}
+
+// OnSuccess implements RetryObserver
+func (b *RetryBudget) OnSuccess(ctx context.Context) {
+	b.mu.Lock()
+	b.tokens = math.Min(b.tokens+b.tokenRatio, b.maxTokens)
+	b.mu.Unlock()
+	observeRetrySuccess(ctx, b.next)
+}
+
+// Tokens returns the number of retries currently available
+func (b *RetryBudget) Tokens() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.tokens
+}
+
+// retryDelay asks the retry policy of the call whether a failed attempt is retried
+func (c *Client) retryDelay(ctx context.Context, cfg *requestConfig, attempt *RetryAttempt) (time.Duration, bool) {
+	attempt.Operation = cfg.operation
+	var apiErr *apierror.APIError
+	if attempt.Headers == nil && errors.As(attempt.Err, &apiErr) {
+		attempt.Headers = apiErr.Headers
+	}
+	attempt.clock, attempt.jitter = c.clock, c.jitter
+	// Context deadlines are wall-clock times, whatever the client clock tells
+	if deadline, ok := ctx.Deadline(); ok {
+		attempt.Remaining = time.Until(deadline)
+		attempt.HasDeadline = true
+	}
+	return cfg.retryPolicy.Retry(ctx, attempt)
+}
+
+// isRetryableError reports whether an error is transient
+func isRetryableError(err error) bool {
+	var waitErr *rateLimitDeadlineError
+	if errors.As(err, &waitErr) {
+		return false
+	}
+
+	var apiErr *apierror.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable()
+	}
+
+	// Network errors are retryable
+	var connErr *apierror.ConnectionError
+	var timeoutErr *apierror.ConnectionTimeoutError
+	return errors.As(err, &connErr) || errors.As(err, &timeoutErr)
+}
+
+// serverRetryAfter returns the wait requested by Retry-After or X-RateLimit-Reset
+func serverRetryAfter(headers http.Header, now time.Time) (time.Duration, bool) {
+	if retryAfter := headers.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.ParseFloat(retryAfter, 64); err == nil && seconds >= 0 {
+			return time.Duration(seconds * float64(time.Second)), true
+		}
+		if retryTime, err := http.ParseTime(retryAfter); err == nil {
+			return max(retryTime.Sub(now), 0), true
+		}
+	}
+	if rateLimitReset := headers.Get("X-RateLimit-Reset"); rateLimitReset != "" {
+		if timestamp, err := strconv.ParseFloat(rateLimitReset, 64); err == nil {
+			return max(time.Unix(int64(timestamp), 0).Sub(now), 0), true
+		}
+	}
+	return 0, false
+}
+
+// withinDeadline reports whether the next attempt can start before the context deadline
+func withinDeadline(attempt *RetryAttempt, backoff time.Duration) bool {
+	return !attempt.HasDeadline || backoff < attempt.Remaining
+}
+
+// observeRetrySuccess reports a successful call to policies that track them
+func observeRetrySuccess(ctx context.Context, policy RetryPolicy) {
+	if observer, ok := policy.(RetryObserver); ok {
+		observer.OnSuccess(ctx)
+	}
+}
+
+// orDefaultRetryPolicy returns policy, or DefaultRetryPolicy when policy is nil
+func orDefaultRetryPolicy(policy RetryPolicy) RetryPolicy {
+	if policy == nil {
+		return DefaultRetryPolicy{}
+	}
+	return policy
+}
+
+// getDurationValue returns value, or def when value is not positive
+func getDurationValue(value, def time.Duration) time.Duration {
+	if value <= 0 {
+		return def
+	}
+	return value
+}
@@ -0,0 +1,233 @@
+package payos
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/payOSHQ/payos-lib-golang/v2/apierror"
+)
+
+func TestDefaultRetryPolicy(t *testing.T) {
+	ctx := context.Background()
+	policy := DefaultRetryPolicy{}
+	serverErr := apierror.GenerateError(http.StatusServiceUnavailable, "", "unavailable", nil)
+
+	tests := []struct {
+		name    string
+		attempt RetryAttempt
+		want    bool
+		backoff time.Duration
+	}{
+		{"not retryable", RetryAttempt{Err: apierror.GenerateError(http.StatusBadRequest, apierror.CodeInvalidParams, "bad", nil)}, false, 0},
+		{"retry after seconds", RetryAttempt{Err: serverErr, Headers: http.Header{"Retry-After": {"2"}}}, true, 2 * time.Second},
+		{"retry after beyond deadline", RetryAttempt{Err: serverErr, Headers: http.Header{"Retry-After": {"2"}}, Remaining: time.Second, HasDeadline: true}, false, 0},
+		{"retry after within deadline", RetryAttempt{Err: serverErr, Headers: http.Header{"Retry-After": {"2"}}, Remaining: 3 * time.Second, HasDeadline: true}, true, 2 * time.Second},
+		{"connection error", RetryAttempt{Err: apierror.NewConnectionError("reset", nil)}, true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backoff, ok := policy.Retry(ctx, &tt.attempt)
+			if ok != tt.want {
+				t.Fatalf("Retry() = %v, want %v", ok, tt.want)
+			}
+			if tt.backoff > 0 && backoff != tt.backoff {
+				t.Errorf("backoff = %v, want %v", backoff, tt.backoff)
+			}
+		})
+	}
+
+	date := time.Now().Add(90 * time.Second).UTC().Format(http.TimeFormat)
+	attempt := &RetryAttempt{Err: serverErr, Headers: http.Header{"Retry-After": {date}}}
+	if backoff, ok := (DefaultRetryPolicy{MaxRetryAfter: 2 * time.Minute}).Retry(ctx, attempt); !ok || backoff < 80*time.Second {
+		t.Errorf("Retry() with HTTP date = %v, %v", backoff, ok)
+	}
+
+	// Waits that are not positive or over the cap fall back to the exponential backoff
+	past := time.Now().Add(-time.Minute)
+	fallbacks := map[string]http.Header{
+		"retry after too long":  {"Retry-After": {"120"}},
+		"retry after zero":      {"Retry-After": {"0"}},
+		"retry after past date": {"Retry-After": {past.UTC().Format(http.TimeFormat)}},
+		"reset in the past":     {"X-RateLimit-Reset": {strconv.FormatInt(past.Unix(), 10)}},
+		"reset too far":         {"X-RateLimit-Reset": {strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)}},
+	}
+	for name, headers := range fallbacks {
+		backoff, ok := policy.Retry(ctx, &RetryAttempt{Err: serverErr, Headers: headers})
+		if !ok || backoff > DefaultInitialBackoff || backoff < DefaultInitialBackoff*3/4 {
+			t.Errorf("%s: Retry() = %v, %v, want the exponential backoff", name, backoff, ok)
+		}
+	}
+
+	for i, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second} {
+		backoff := (DefaultRetryPolicy{InitialBackoff: time.Second, MaxBackoff: 4 * time.Second}).Backoff(&RetryAttempt{Attempt: i})
+		if backoff > want || backoff < want*3/4 {
+			t.Errorf("Backoff(attempt %d) = %v, want about %v", i, backoff, want)
+		}
+	}
+}
+
+func TestRetryPolicies(t *testing.T) {
+	ctx := context.Background()
+	serverErr := apierror.GenerateError(http.StatusInternalServerError, "", "internal", nil)
+
+	requireKey := RequireIdempotencyKey(nil)
+	if _, ok := requireKey.Retry(ctx, &RetryAttempt{Method: http.MethodPost, Err: serverErr}); ok {
+		t.Error("RequireIdempotencyKey retried a POST without key")
+	}
+	if _, ok := requireKey.Retry(ctx, &RetryAttempt{Method: http.MethodPost, IdempotencyKey: "key", Err: serverErr}); !ok {
+		t.Error("RequireIdempotencyKey did not retry a POST with key")
+	}
+	if _, ok := requireKey.Retry(ctx, &RetryAttempt{Method: http.MethodGet, Err: serverErr}); !ok {
+		t.Error("RequireIdempotencyKey did not retry a GET")
+	}
+
+	codes := RetryOnCodes(nil, apierror.CodeOrderCodeExists)
+	listedErr := apierror.GenerateError(http.StatusOK, apierror.CodeOrderCodeExists, "exists", nil)
+	if _, ok := codes.Retry(ctx, &RetryAttempt{Err: listedErr}); !ok {
+		t.Error("RetryOnCodes did not retry a listed code")
+	}
+	if _, ok := codes.Retry(ctx, &RetryAttempt{Err: listedErr, Remaining: time.Millisecond, HasDeadline: true}); ok {
+		t.Error("RetryOnCodes retried past the deadline")
+	}
+	if _, ok := codes.Retry(ctx, &RetryAttempt{Err: apierror.GenerateError(http.StatusOK, apierror.CodeInvalidParams, "params", nil)}); ok {
+		t.Error("RetryOnCodes retried an unlisted code")
+	}
+}
+
+func TestRetryBudget(t *testing.T) {
+	ctx := context.Background()
+	budget := NewRetryBudget(RetryPolicyFunc(func(ctx context.Context, attempt *RetryAttempt) (time.Duration, bool) {
+		return 0, true
+	}), 2, 0.5)
+
+	for i := 0; i < 2; i++ {
+		if _, ok := budget.Retry(ctx, &RetryAttempt{}); !ok {
+			t.Fatalf("Retry() %d refused with budget left", i)
+		}
+	}
+	if _, ok := budget.Retry(ctx, &RetryAttempt{}); ok {
+		t.Fatal("Retry() allowed with an empty budget")
+	}
+
+	budget.OnSuccess(ctx)
+	budget.OnSuccess(ctx)
+	if got := budget.Tokens(); got != 1 {
+		t.Fatalf("Tokens() = %v, want 1", got)
+	}
+	if _, ok := budget.Retry(ctx, &RetryAttempt{}); !ok {
+		t.Error("Retry() refused after successful calls")
+	}
+}
+
+func TestClientRetryPolicy(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	var seen []RetryAttempt
+	client, err := NewPayOS(&PayOSOptions{
+		ClientId:    "client-id",
+		ApiKey:      "api-key",
+		ChecksumKey: "checksum-key",
+		BaseURL:     srv.URL,
+		MaxRetries:  3,
+		RetryPolicy: RetryPolicyFunc(func(ctx context.Context, attempt *RetryAttempt) (time.Duration, bool) {
+			seen = append(seen, *attempt)
+			return time.Millisecond, true
+		}),
+	})
+	if err != nil {
+		t.Fatalf("NewPayOS() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if _, err := client.PayoutsAccount.Balance(ctx); err == nil {
+		t.Fatal("Balance() expected error")
+	}
+	if got := atomic.LoadInt32(&requests); got != 4 || len(seen) != 3 {
+		t.Fatalf("requests = %d, policy calls = %d", got, len(seen))
+	}
+	first := seen[0]
+	if first.Operation != "PayoutsAccount.Balance" || first.Method != "GET" || first.Attempt != 0 || !first.HasDeadline || first.Remaining <= 0 {
+		t.Errorf("RetryAttempt = %+v", first)
+	}
+
+	atomic.StoreInt32(&requests, 0)
+	_, err = client.PayoutsAccount.Balance(ctx, WithRetryPolicy(RetryPolicyFunc(func(ctx context.Context, attempt *RetryAttempt) (time.Duration, bool) {
+		return 0, false
+	})))
+	if err == nil || atomic.LoadInt32(&requests) != 1 {
+		t.Errorf("WithRetryPolicy: requests = %d", atomic.LoadInt32(&requests))
+	}
+}
+
+func TestRequireIdempotencyKeyHeaders(t *testing.T) {
+	var requests int32
+	var keys []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		keys = append(keys, r.Header.Get("x-idempotency-key"))
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	client, err := NewPayOS(&PayOSOptions{
+		ClientId:    "client-id",
+		ApiKey:      "api-key",
+		ChecksumKey: "checksum-key",
+		BaseURL:     srv.URL,
+		MaxRetries:  1,
+		RetryPolicy: RequireIdempotencyKey(RetryPolicyFunc(func(ctx context.Context, attempt *RetryAttempt) (time.Duration, bool) {
+			return time.Millisecond, true
+		})),
+	})
+	if err != nil {
+		t.Fatalf("NewPayOS() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		headers  map[string]string
+		opts     []RequestOption
+		wantKey  string
+		requests int32
+	}{
+		{"no key", nil, nil, "", 1},
+		{"request options header", map[string]string{"X-Idempotency-Key": "opts-key"}, nil, "opts-key", 2},
+		{"WithHeader", nil, []RequestOption{WithHeader("x-idempotency-key", "header-key")}, "header-key", 2},
+		{"WithHeader over request options", map[string]string{"x-idempotency-key": "opts-key"}, []RequestOption{WithHeader("X-Idempotency-Key", "header-key")}, "header-key", 2},
+		{"WithIdempotencyKey over WithHeader", nil, []RequestOption{WithHeader("x-idempotency-key", "header-key"), WithIdempotencyKey("option-key")}, "option-key", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt32(&requests, 0)
+			keys = nil
+			_, err := client.Client.Request(context.Background(), &RequestOptions{
+				Method:  http.MethodPost,
+				Path:    "/v1/payouts",
+				Body:    map[string]string{"referenceId": "ref"},
+				Headers: tt.headers,
+			}, tt.opts...)
+			if got := atomic.LoadInt32(&requests); got != tt.requests {
+				t.Fatalf("requests = %d, want %d", got, tt.requests)
+			}
+			if keys[0] != tt.wantKey {
+				t.Errorf("sent x-idempotency-key = %q, want %q", keys[0], tt.wantKey)
+			}
+			var opErr *apierror.OperationError
+			if !errors.As(err, &opErr) || opErr.IdempotencyKey != tt.wantKey {
+				t.Errorf("Request() error = %v, want an OperationError with key %q", err, tt.wantKey)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+// Package signature creates and verifies payOS HMAC signatures.
+//
+// payOS uses three canonicalization schemes depending on the endpoint:
+//
+//   - SchemePaymentRequest signs "amount=..&cancelUrl=..&description=..&orderCode=..&returnUrl=.."
+//     when creating a payment link.
+//   - SchemeBody signs the sorted "key=value" pairs of an object, used by payment request
+//     responses and payment webhooks.
+//   - SchemeHeader signs a sorted, URL encoded query string, used by payout requests and
+//     responses in the x-signature header.
+//
+// Data may be a struct, a map or raw JSON ([]byte or json.RawMessage). Raw JSON is
+// decoded with json.Number, so large integers are signed without losing precision.
+//
+//	signer := signature.NewSigner(signature.SchemeBody, checksumKey)
+//	sig, err := signer.Sign(data)
+//
+//	verifier := signature.NewVerifier(signature.SchemeBody, checksumKey)
+//	if err := verifier.Verify(data, sig); err != nil {
+//	    var mismatch *signature.MismatchError
+//	    if errors.As(err, &mismatch) {
+//	        log.Println(mismatch.Canonical)
+//	    }
+//	}
+package signature
+
+import (
+	"crypto/hmac"
+	"fmt"
+
+	"github.com/payOSHQ/payos-lib-golang/v2/apierror"
+	"github.com/payOSHQ/payos-lib-golang/v2/internal/crypto"
+)
+
+// Scheme identifies how data is canonicalized before signing
+type Scheme string
+
+const (
+	// SchemePaymentRequest is used to sign create payment link requests
+	SchemePaymentRequest Scheme = "create-payment-link"
+	// SchemeBody is used to sign payment request responses and webhooks
+	SchemeBody Scheme = "body"
+	// SchemeHeader is used to sign payout requests and responses
+	SchemeHeader Scheme = "header"
+)
+
+// Signer creates signatures for a single scheme and checksum key
+type Signer struct {
+	scheme Scheme
+	key    string
+}
+
+// NewSigner creates a Signer for the scheme using the checksum key
+func NewSigner(scheme Scheme, checksumKey string) *Signer {
+	return &Signer{
+		scheme: scheme,
+		key:    checksumKey,
+	}
+}
+
+// Scheme returns the scheme of the signer
+func (s *Signer) Scheme() Scheme {
+	return s.scheme
+}
+
+// Canonical returns the string that is signed for data
+func (s *Signer) Canonical(data interface{}) (string, error) {
+	switch s.scheme {
+	case SchemePaymentRequest:
+		return crypto.CanonicalPaymentRequest(data)
+	case SchemeBody:
+		return crypto.SortObjByKey(data)
+	case SchemeHeader:
+		return crypto.CanonicalQueryString(data, nil)
+	default:
+		return "", fmt.Errorf("unsupported signature scheme: %s", s.scheme)
+	}
+}
+
+// Sign returns the hex encoded HMAC-SHA256 signature of data
+func (s *Signer) Sign(data interface{}) (string, error) {
+	canonical, err := s.Canonical(data)
+	if err != nil {
+		return "", err
+	}
+	return crypto.HMAC("sha256", s.key, canonical)
+}
+
+// Verifier checks signatures for a single scheme and checksum key
+type Verifier struct {
+	signer *Signer
+}
+
+// NewVerifier creates a Verifier for the scheme using the checksum key
+func NewVerifier(scheme Scheme, checksumKey string) *Verifier {
+	return &Verifier{
+		signer: NewSigner(scheme, checksumKey),
+	}
+}
+
+// Verify checks that signature is the signature of data
+// The comparison runs in constant time. On mismatch a *MismatchError is returned
+func (v *Verifier) Verify(data interface{}, signature string) error {
+	canonical, err := v.signer.Canonical(data)
+	if err != nil {
+		return err
+	}
+	expected, err := crypto.HMAC("sha256", v.signer.key, canonical)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return &MismatchError{
+			Scheme:    v.signer.scheme,
+			Canonical: canonical,
+			Expected:  expected,
+			Received:  signature,
+		}
+	}
+	return nil
+}
+
+// MismatchError is returned when a signature does not match the data
+type MismatchError struct {
+	Scheme    Scheme
+	Canonical string
+	Expected  string
+	Received  string
+}
+
+func (e *MismatchError) Error() string {
+	return fmt.Sprintf("signature mismatch (scheme %s)", e.Scheme)
+}
+
+// Is reports whether the target is apierror.ErrInvalidSignature
+func (e *MismatchError) Is(target error) bool {
+	return target == apierror.ErrInvalidSignature
+}
+
+// Sign is a shorthand for NewSigner(scheme, checksumKey).Sign(data)
+func Sign(scheme Scheme, checksumKey string, data interface{}) (string, error) {
+	return NewSigner(scheme, checksumKey).Sign(data)
+}
+
+// Verify is a shorthand for NewVerifier(scheme, checksumKey).Verify(data, signature)
+func Verify(scheme Scheme, checksumKey string, data interface{}, signature string) error {
+	return NewVerifier(scheme, checksumKey).Verify(data, signature)
+}
@@ -0,0 +1,99 @@
+package signature
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/payOSHQ/payos-lib-golang/v2/apierror"
+)
+
+const testChecksumKey = "test-checksum-key"
+
+func TestCanonical(t *testing.T) {
+	data := map[string]interface{}{
+		"orderCode":   123,
+		"amount":      2000,
+		"description": "payment order",
+		"cancelUrl":   "https://example.com/cancel",
+		"returnUrl":   "https://example.com/return",
+		"items":       []interface{}{map[string]interface{}{"name": "a"}},
+	}
+
+	tests := []struct {
+		scheme Scheme
+		want   string
+	}{
+		{SchemePaymentRequest, "amount=2000&cancelUrl=https://example.com/cancel&description=payment order&orderCode=123&returnUrl=https://example.com/return"},
+		{SchemeBody, `amount=2000&cancelUrl=https://example.com/cancel&description=payment order&items=[{"name":"a"}]&orderCode=123&returnUrl=https://example.com/return`},
+		{SchemeHeader, "amount=2000&cancelUrl=https%3A%2F%2Fexample.com%2Fcancel&description=payment%20order&items=%5B%7B%22name%22%3A%22a%22%7D%5D&orderCode=123&returnUrl=https%3A%2F%2Fexample.com%2Freturn"},
+	}
+
+	for _, tt := range tests {
+		got, err := NewSigner(tt.scheme, testChecksumKey).Canonical(data)
+		if err != nil {
+			t.Fatalf("Canonical(%s) error = %v", tt.scheme, err)
+		}
+		if got != tt.want {
+			t.Errorf("Canonical(%s) = %q, want %q", tt.scheme, got, tt.want)
+		}
+	}
+}
+
+func TestSignatureGeneration(t *testing.T) {
+	type order struct {
+		OrderCode int64  `json:"orderCode"`
+		Reference string `json:"reference"`
+	}
+	raw := json.RawMessage(`{"reference":"TF1","orderCode":9007199254740993}`)
+
+	for _, scheme := range []Scheme{SchemeBody, SchemeHeader} {
+		fromStruct, err := Sign(scheme, testChecksumKey, order{OrderCode: 9007199254740993, Reference: "TF1"})
+		if err != nil {
+			t.Fatalf("Sign(%s, struct) error = %v", scheme, err)
+		}
+		fromRaw, err := Sign(scheme, testChecksumKey, raw)
+		if err != nil {
+			t.Fatalf("Sign(%s, raw) error = %v", scheme, err)
+		}
+		if fromStruct != fromRaw {
+			t.Errorf("Sign(%s) struct = %s, raw = %s", scheme, fromStruct, fromRaw)
+		}
+	}
+}
+
+func TestCanonicalNumbers(t *testing.T) {
+	data := map[string]interface{}{"amount": 1000000, "orderCode": int64(9007199254740993)}
+
+	got, err := NewSigner(SchemeHeader, testChecksumKey).Canonical(data)
+	if err != nil {
+		t.Fatalf("Canonical() error = %v", err)
+	}
+	if want := "amount=1000000&orderCode=9007199254740993"; got != want {
+		t.Errorf("Canonical() = %q, want %q", got, want)
+	}
+}
+
+func TestSignatureVerification(t *testing.T) {
+	data := map[string]interface{}{"amount": 1000, "reference": "TF1"}
+	sig, err := Sign(SchemeBody, testChecksumKey, data)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	if err := Verify(SchemeBody, testChecksumKey, data, sig); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+
+	err = Verify(SchemeBody, testChecksumKey, map[string]interface{}{"amount": 1, "reference": "TF1"}, sig)
+	var mismatch *MismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("Verify() error = %v, want *MismatchError", err)
+	}
+	if mismatch.Canonical != "amount=1&reference=TF1" || mismatch.Received != sig {
+		t.Errorf("MismatchError = %+v", mismatch)
+	}
+	if !errors.Is(err, apierror.ErrInvalidSignature) {
+		t.Errorf("errors.Is(err, ErrInvalidSignature) = false")
+	}
+}
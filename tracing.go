@@ -0,0 +1,215 @@
+package payos
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Observer starts the spans of a client, see PayOSOptions.Observer
+// It mirrors the shape of an OpenTelemetry tracer so an adapter is a few lines
+// Each call gets an operation span, such as PaymentRequests.Create, with a child span per HTTP attempt
+type Observer interface {
+	// StartSpan starts a span as a child of the span in ctx and returns a context carrying it
+	StartSpan(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+// Span is a unit of work started by an Observer
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	AddEvent(name string, attrs ...Attribute)
+	RecordError(err error)
+	End()
+
+	// SpanContext identifies the span for traceparent propagation
+	// An invalid SpanContext disables the traceparent header
+	SpanContext() SpanContext
+}
+
+// Attribute is a key and value annotating a span
+// Values are strings, bools, ints or float64
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// SpanContext is the W3C trace context of a span
+type SpanContext struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+	Sampled bool
+}
+
+// IsValid reports whether the trace and span IDs are set
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != [16]byte{} && sc.SpanID != [8]byte{}
+}
+
+// TraceParent formats the span context as a traceparent header value
+func (sc SpanContext) TraceParent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return fmt.Sprintf("00-%s-%s-%s", hex.EncodeToString(sc.TraceID[:]), hex.EncodeToString(sc.SpanID[:]), flags)
+}
+
+// nopObserver is the Observer of clients without PayOSOptions.Observer
+type nopObserver struct{}
+
+func (nopObserver) StartSpan(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	return ctx, nopSpan{}
+}
+
+type nopSpan struct{}
+
+func (nopSpan) SetAttributes(attrs ...Attribute)         {}
+func (nopSpan) AddEvent(name string, attrs ...Attribute) {}
+func (nopSpan) RecordError(err error)                    {}
+func (nopSpan) End()                                     {}
+func (nopSpan) SpanContext() SpanContext                 { return SpanContext{} }
+
+// startOperationSpan starts the span of an API call or download
+// Calls made without an operation name are named after the method and path
+func (c *Client) startOperationSpan(ctx context.Context, cfg *requestConfig, method, path string) (context.Context, Span) {
+	name := cfg.operation
+	if name == "" {
+		name = method + " " + path
+	}
+	return c.observer.StartSpan(ctx, name,
+		Attribute{Key: "http.request.method", Value: method},
+		Attribute{Key: "url.path", Value: path},
+	)
+}
+
+// endOperationSpan ends the span of an API call with its outcome
+func endOperationSpan(span Span, attempts int, err error) {
+	span.SetAttributes(Attribute{Key: "payos.attempts", Value: attempts})
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
+
+// retryEvent records the backoff before a retry on the operation span
+func retryEvent(span Span, attempt int, backoff time.Duration) {
+	span.AddEvent("retry",
+		Attribute{Key: "payos.attempt", Value: attempt},
+		Attribute{Key: "payos.retry.backoff_ms", Value: float64(backoff) / float64(time.Millisecond)},
+	)
+}
+
+// startAttemptSpan starts the span of an HTTP attempt
+// The returned context collects the httptrace timings of the attempt
+func (c *Client) startAttemptSpan(ctx context.Context, method, path string, attempt int) (context.Context, Span, *attemptTimings) {
+	if _, ok := c.observer.(nopObserver); ok {
+		return ctx, nopSpan{}, nil
+	}
+	ctx, span := c.observer.StartSpan(ctx, "HTTP "+method,
+		Attribute{Key: "http.request.method", Value: method},
+		Attribute{Key: "url.path", Value: path},
+		Attribute{Key: "payos.attempt", Value: attempt},
+	)
+	timings := &attemptTimings{start: time.Now()}
+	return httptrace.WithClientTrace(ctx, timings.clientTrace()), span, timings
+}
+
+// endAttemptSpan ends the span of an HTTP attempt with its response and timings
+func endAttemptSpan(span Span, timings *attemptTimings, statusCode int, code string, err error) {
+	if timings == nil {
+		return
+	}
+	if statusCode != 0 {
+		span.SetAttributes(Attribute{Key: "http.response.status_code", Value: statusCode})
+	}
+	if code != "" {
+		span.SetAttributes(Attribute{Key: "payos.code", Value: code})
+	}
+	span.SetAttributes(timings.attributes()...)
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
+
+// injectTraceParent propagates the span to payOS unless the request already carries a traceparent
+func injectTraceParent(header http.Header, span Span) {
+	if sc := span.SpanContext(); sc.IsValid() && header.Get("traceparent") == "" {
+		header.Set("traceparent", sc.TraceParent())
+	}
+}
+
+// attemptTimings collects the connection timings of an HTTP attempt
+type attemptTimings struct {
+	start time.Time
+
+	mu           sync.Mutex
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	dns          time.Duration
+	connect      time.Duration
+	tls          time.Duration
+	ttfb         time.Duration
+	reused       bool
+}
+
+func (t *attemptTimings) clientTrace() *httptrace.ClientTrace {
+	record := func(f func()) {
+		t.mu.Lock()
+		f()
+		t.mu.Unlock()
+	}
+	return &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			record(func() { t.reused = info.Reused })
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			record(func() { t.dnsStart = time.Now() })
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			record(func() { t.dns = time.Since(t.dnsStart) })
+		},
+		ConnectStart: func(network, addr string) {
+			record(func() { t.connectStart = time.Now() })
+		},
+		ConnectDone: func(network, addr string, err error) {
+			record(func() { t.connect = time.Since(t.connectStart) })
+		},
+		TLSHandshakeStart: func() {
+			record(func() { t.tlsStart = time.Now() })
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			record(func() { t.tls = time.Since(t.tlsStart) })
+		},
+		GotFirstResponseByte: func() {
+			record(func() { t.ttfb = time.Since(t.start) })
+		},
+	}
+}
+
+// attributes returns the timings in milliseconds, leaving out the phases that did not happen
+func (t *attemptTimings) attributes() []Attribute {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	attrs := []Attribute{{Key: "http.connection.reused", Value: t.reused}}
+	for _, timing := range []struct {
+		key string
+		d   time.Duration
+	}{
+		{"http.dns_ms", t.dns},
+		{"http.connect_ms", t.connect},
+		{"http.tls_ms", t.tls},
+		{"http.ttfb_ms", t.ttfb},
+	} {
+		if timing.d > 0 {
+			attrs = append(attrs, Attribute{Key: timing.key, Value: float64(timing.d) / float64(time.Millisecond)})
+		}
+	}
+	return attrs
+}
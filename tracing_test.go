@@ -0,0 +1,167 @@
+package payos
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/payOSHQ/payos-lib-golang/v2/payostest"
+)
+
+// recordingObserver records spans with sequential IDs
+type recordingObserver struct {
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+type recordedSpan struct {
+	name   string
+	parent *recordedSpan
+	sc     SpanContext
+	attrs  map[string]interface{}
+	events []string
+	err    error
+	ended  bool
+}
+
+type spanKey struct{}
+
+func (o *recordingObserver) StartSpan(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	span := &recordedSpan{name: name, attrs: make(map[string]interface{})}
+	span.sc.SpanID[7] = byte(len(o.spans) + 1)
+	span.sc.TraceID[15] = 1
+	span.sc.Sampled = true
+	if parent, ok := ctx.Value(spanKey{}).(*recordedSpan); ok {
+		span.parent = parent
+	}
+	span.SetAttributes(attrs...)
+	o.spans = append(o.spans, span)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func (o *recordingObserver) find(name string) []*recordedSpan {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var spans []*recordedSpan
+	for _, span := range o.spans {
+		if span.name == name {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+func (s *recordedSpan) SetAttributes(attrs ...Attribute) {
+	for _, attr := range attrs {
+		s.attrs[attr.Key] = attr.Value
+	}
+}
+
+func (s *recordedSpan) AddEvent(name string, attrs ...Attribute) { s.events = append(s.events, name) }
+func (s *recordedSpan) RecordError(err error)                    { s.err = err }
+func (s *recordedSpan) End()                                     { s.ended = true }
+func (s *recordedSpan) SpanContext() SpanContext                 { return s.sc }
+
+func TestObserver(t *testing.T) {
+	srv := payostest.NewServer(nil)
+	t.Cleanup(srv.Close)
+	observer := &recordingObserver{}
+	client, err := NewPayOS(&PayOSOptions{
+		ClientId:    srv.ClientId,
+		ApiKey:      srv.ApiKey,
+		ChecksumKey: srv.ChecksumKey,
+		BaseURL:     srv.URL,
+		Observer:    observer,
+		RetryPolicy: RetryPolicyFunc(func(ctx context.Context, attempt *RetryAttempt) (time.Duration, bool) {
+			return time.Millisecond, isRetryableError(attempt.Err)
+		}),
+	})
+	if err != nil {
+		t.Fatalf("NewPayOS() error = %v", err)
+	}
+	ctx := context.Background()
+
+	// The first attempt fails with a 503, the traceparent of each attempt is captured
+	var traceparents []string
+	capture := func(next RequestHandler) RequestHandler {
+		return func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			traceparents = append(traceparents, req.Header.Get("traceparent"))
+			if AttemptFromContext(ctx) == 0 {
+				return &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(""))}, nil
+			}
+			return next(ctx, req)
+		}
+	}
+	if _, err := client.Payouts.Create(ctx, testPayoutRequest("ref-trace"), nil, WithMiddleware(capture)); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	ops := observer.find("Payouts.Create")
+	if len(ops) != 1 {
+		t.Fatalf("operation spans = %d, want 1", len(ops))
+	}
+	op := ops[0]
+	if !op.ended || op.err != nil || op.attrs["payos.attempts"] != 2 || len(op.events) != 1 || op.events[0] != "retry" {
+		t.Errorf("operation span = %+v", op)
+	}
+
+	attempts := observer.find("HTTP POST")
+	if len(attempts) != 2 {
+		t.Fatalf("attempt spans = %d, want 2", len(attempts))
+	}
+	for i, span := range attempts {
+		if span.parent != op || !span.ended || span.attrs["payos.attempt"] != i {
+			t.Errorf("attempt span %d = %+v", i, span)
+		}
+		if traceparents[i] != span.sc.TraceParent() {
+			t.Errorf("traceparent %d = %q, want %q", i, traceparents[i], span.sc.TraceParent())
+		}
+	}
+	if first := attempts[0]; first.attrs["http.response.status_code"] != http.StatusServiceUnavailable || first.err == nil {
+		t.Errorf("failed attempt span = %+v", first)
+	}
+	second := attempts[1]
+	if second.attrs["http.response.status_code"] != http.StatusOK || second.attrs["payos.code"] != "00" || second.attrs["payos.signature.valid"] != true {
+		t.Errorf("successful attempt span = %+v", second.attrs)
+	}
+	if _, ok := second.attrs["http.ttfb_ms"]; !ok {
+		t.Errorf("attempt span has no httptrace timings: %+v", second.attrs)
+	}
+
+	tamper := func(next RequestHandler) RequestHandler {
+		return func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			resp, err := next(ctx, req)
+			if err == nil {
+				resp.Header.Set("x-signature", "tampered")
+			}
+			return resp, err
+		}
+	}
+	_, err = client.PayoutsAccount.Balance(ctx, WithMiddleware(tamper), WithMaxRetries(0))
+	balance := observer.find("PayoutsAccount.Balance")
+	if len(balance) != 1 || !errors.Is(balance[0].err, err) {
+		t.Fatalf("Balance() spans = %+v, error = %v", balance, err)
+	}
+	if get := observer.find("HTTP GET"); len(get) != 1 || get[0].attrs["payos.signature.valid"] != false {
+		t.Errorf("attempt span with tampered signature = %+v", get)
+	}
+}
+
+func TestSpanContextTraceParent(t *testing.T) {
+	var sc SpanContext
+	if sc.IsValid() {
+		t.Error("zero SpanContext is valid")
+	}
+	sc.TraceID[0], sc.TraceID[15] = 0x4b, 0xf9
+	sc.SpanID[0], sc.SpanID[7] = 0x00, 0xf0
+	if got, want := sc.TraceParent(), "00-4b0000000000000000000000000000f9-00000000000000f0-00"; got != want {
+		t.Errorf("TraceParent() = %q, want %q", got, want)
+	}
+}
@@ -1,5 +1,11 @@
 package payos
 
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
 // ========================
 // Common Response Types
 // ========================
@@ -12,6 +18,40 @@ type PayOSResponseType struct {
 	Signature *string     `json:"signature"`
 }
 
+// RawResponse holds the HTTP metadata and raw payload of an API call
+// It is filled by the WithRawResponse request option
+type RawResponse struct {
+	// StatusCode and Header are taken from the last HTTP response
+	StatusCode int
+	Header     http.Header
+
+	// Body is the raw response body exactly as received
+	Body []byte
+
+	// Code, Desc and Data are the fields of the payOS response envelope
+	// Data is the exact payload the signature was computed on
+	Code string
+	Desc string
+	Data json.RawMessage
+
+	// Signature is the response signature from the body or the x-signature header
+	Signature string
+
+	// Latency is the total duration of the call including retries and backoff
+	Latency time.Duration
+
+	// Retries is the number of retries performed before the last response
+	Retries int
+}
+
+// rawResponse is the standard API response wrapper with undecoded data
+type rawResponse struct {
+	Code      string          `json:"code"`
+	Desc      string          `json:"desc"`
+	Data      json.RawMessage `json:"data"`
+	Signature *string         `json:"signature"`
+}
+
 // FileDownloadResponse represents a file download response
 type FileDownloadResponse struct {
 	Filename    *string `json:"filename,omitempty"`
@@ -0,0 +1,297 @@
+package payos
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/payOSHQ/payos-lib-golang/v2/apierror"
+)
+
+// DefaultWebhookDedupeTTL is how long a processed webhook is remembered by default
+const DefaultWebhookDedupeTTL = 72 * time.Hour
+
+// WebhookStore records the keys of processed webhooks
+type WebhookStore interface {
+	// Claim records key for ttl and reports whether it was not already recorded
+	Claim(ctx context.Context, key string, ttl time.Duration) (bool, error)
+
+	// Release forgets key so the webhook can be processed again
+	Release(ctx context.Context, key string) error
+}
+
+// WebhookDeduper detects redelivered webhooks
+// A webhook is identified by the paymentLinkId and reference of its data
+// Webhooks missing either of them are never reported as duplicates
+type WebhookDeduper struct {
+	store WebhookStore
+	ttl   time.Duration
+}
+
+// NewWebhookDeduper creates a deduper backed by store
+// A nil store uses a MemoryWebhookStore and a ttl of zero uses DefaultWebhookDedupeTTL
+func NewWebhookDeduper(store WebhookStore, ttl time.Duration) *WebhookDeduper {
+	if store == nil {
+		store = NewMemoryWebhookStore(nil)
+	}
+	if ttl <= 0 {
+		ttl = DefaultWebhookDedupeTTL
+	}
+	return &WebhookDeduper{
+		store: store,
+		ttl:   ttl,
+	}
+}
+
+// WebhookKey returns the deduplication key of webhook data
+// It is empty when the paymentLinkId or the reference is missing
+func WebhookKey(data *WebhookData) string {
+	if data == nil || data.PaymentLinkId == "" || data.Reference == "" {
+		return ""
+	}
+	return data.PaymentLinkId + ":" + data.Reference
+}
+
+// Claim marks the webhook as processed
+// It returns an error matching apierror.ErrDuplicateWebhook if it was already claimed
+func (d *WebhookDeduper) Claim(ctx context.Context, data *WebhookData) error {
+	return d.claimKey(ctx, WebhookKey(data))
+}
+
+// Release forgets the webhook, typically after its processing failed
+func (d *WebhookDeduper) Release(ctx context.Context, data *WebhookData) error {
+	key := WebhookKey(data)
+	if key == "" {
+		return nil
+	}
+	return d.store.Release(ctx, key)
+}
+
+// claimKey claims a deduplication key
+// An empty key identifies no webhook and is never claimed
+func (d *WebhookDeduper) claimKey(ctx context.Context, key string) error {
+	if key == "" {
+		return nil
+	}
+	claimed, err := d.store.Claim(ctx, key, d.ttl)
+	if err != nil {
+		return apierror.NewWebhookError(fmt.Sprintf("failed to record webhook %s: %v", key, err))
+	}
+	if !claimed {
+		return apierror.NewDuplicateWebhookError(key)
+	}
+	return nil
+}
+
+// DefaultWebhookStoreCleanupInterval is how often the built-in stores remove expired keys by default
+const DefaultWebhookStoreCleanupInterval = 10 * time.Minute
+
+// WebhookStoreOptions configures MemoryWebhookStore and FileWebhookStore
+type WebhookStoreOptions struct {
+	// Clock tells when keys expire
+	// Defaults to SystemClock
+	Clock Clock
+
+	// CleanupInterval is how often expired keys are removed during a claim
+	// An expired key is never reported as claimed, even before it is removed
+	// Defaults to DefaultWebhookStoreCleanupInterval
+	CleanupInterval time.Duration
+}
+
+// expiringKeys is a set of keys recorded until their own expiry
+// Lookups ignore expired keys, which are removed at most once per cleanup interval
+type expiringKeys struct {
+	expires   map[string]time.Time
+	clock     Clock
+	interval  time.Duration
+	lastSweep time.Time
+}
+
+func newExpiringKeys(opts *WebhookStoreOptions) expiringKeys {
+	var o WebhookStoreOptions
+	if opts != nil {
+		o = *opts
+	}
+	clock := orSystemClock(o.Clock)
+	return expiringKeys{
+		expires:   make(map[string]time.Time),
+		clock:     clock,
+		interval:  getDurationValue(o.CleanupInterval, DefaultWebhookStoreCleanupInterval),
+		lastSweep: clock.Now(),
+	}
+}
+
+// claim records key for ttl unless it is recorded and not expired
+// It returns the previous expiry of key so a failed claim can be undone
+func (k *expiringKeys) claim(key string, ttl time.Duration) (claimed bool, previous time.Time, hadPrevious bool) {
+	now := k.clock.Now()
+	if now.Sub(k.lastSweep) >= k.interval {
+		k.lastSweep = now
+		removeExpired(k.expires, now)
+	}
+
+	previous, hadPrevious = k.expires[key]
+	if hadPrevious && now.Before(previous) {
+		return false, previous, true
+	}
+	k.expires[key] = now.Add(ttl)
+	return true, previous, hadPrevious
+}
+
+// ========================
+// Memory store
+// ========================
+
+// MemoryWebhookStore keeps processed webhook keys in memory until they expire
+type MemoryWebhookStore struct {
+	mu   sync.Mutex
+	keys expiringKeys
+}
+
+// NewMemoryWebhookStore creates an empty in-memory webhook store
+func NewMemoryWebhookStore(opts *WebhookStoreOptions) *MemoryWebhookStore {
+	return &MemoryWebhookStore{
+		keys: newExpiringKeys(opts),
+	}
+}
+
+// Claim records key for ttl and reports whether it was not already recorded
+func (s *MemoryWebhookStore) Claim(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	claimed, _, _ := s.keys.claim(key, ttl)
+	return claimed, nil
+}
+
+// Release forgets key
+func (s *MemoryWebhookStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.keys.expires, key)
+	return nil
+}
+
+// ========================
+// File store
+// ========================
+
+// FileWebhookStore keeps processed webhook keys in a JSON file
+// Every claim durably replaces the file, so it suits the webhook volume of a single merchant
+// It survives restarts of a single process but must not be shared between processes
+type FileWebhookStore struct {
+	mu   sync.Mutex
+	path string
+	keys expiringKeys
+}
+
+// NewFileWebhookStore opens the webhook store at path, creating it on the first claim
+func NewFileWebhookStore(path string, opts *WebhookStoreOptions) (*FileWebhookStore, error) {
+	s := &FileWebhookStore{
+		path: path,
+		keys: newExpiringKeys(opts),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook store: %w", err)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &s.keys.expires); err != nil {
+			return nil, fmt.Errorf("failed to parse webhook store %s: %w", path, err)
+		}
+	}
+	return s, nil
+}
+
+// Claim records key for ttl and reports whether it was not already recorded
+func (s *FileWebhookStore) Claim(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	claimed, previous, hadPrevious := s.keys.claim(key, ttl)
+	if !claimed {
+		return false, nil
+	}
+	if err := s.save(); err != nil {
+		if hadPrevious {
+			s.keys.expires[key] = previous
+		} else {
+			delete(s.keys.expires, key)
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Release forgets key
+func (s *FileWebhookStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.keys.expires[key]; !ok {
+		return nil
+	}
+	delete(s.keys.expires, key)
+	return s.save()
+}
+
+// save durably replaces the store file with a synced temporary file
+// It must be called with s.mu held
+func (s *FileWebhookStore) save() error {
+	data, err := json.Marshal(s.keys.expires)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(s.path)
+	tmp, err := os.CreateTemp(dir, filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write webhook store: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write webhook store: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync webhook store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write webhook store: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to write webhook store: %w", err)
+	}
+	syncDir(dir)
+	return nil
+}
+
+// syncDir makes a rename in dir durable
+// It is best effort, as some platforms cannot sync directories
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
+
+// removeExpired deletes the keys expired at now
+func removeExpired(expires map[string]time.Time, now time.Time) {
+	for key, expiresAt := range expires {
+		if !now.Before(expiresAt) {
+			delete(expires, key)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package payos
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/payOSHQ/payos-lib-golang/v2/apierror"
+	"github.com/payOSHQ/payos-lib-golang/v2/payostest"
+	"github.com/payOSHQ/payos-lib-golang/v2/signature"
+)
+
+func TestMemoryWebhookStore(t *testing.T) {
+	ctx := context.Background()
+	clock := payostest.NewFakeClock(time.Now())
+	store := NewMemoryWebhookStore(&WebhookStoreOptions{Clock: clock, CleanupInterval: time.Hour})
+
+	if ok, _ := store.Claim(ctx, "link:ref", time.Minute); !ok {
+		t.Fatal("first Claim() = false")
+	}
+	if ok, _ := store.Claim(ctx, "link:ref", time.Minute); ok {
+		t.Error("second Claim() = true")
+	}
+	store.Claim(ctx, "other:ref", time.Minute)
+
+	// Expired keys are claimable before the cleanup removes them
+	clock.Advance(time.Minute)
+	if ok, _ := store.Claim(ctx, "link:ref", time.Hour); !ok {
+		t.Error("Claim() after expiry = false")
+	}
+	if len(store.keys.expires) != 2 {
+		t.Errorf("%d keys before the cleanup interval, want 2", len(store.keys.expires))
+	}
+	clock.Advance(time.Hour - time.Second)
+	store.Claim(ctx, "new:ref", time.Hour)
+	if _, ok := store.keys.expires["other:ref"]; ok {
+		t.Error("expired key kept after the cleanup interval")
+	}
+
+	store.Release(ctx, "new:ref")
+	if ok, _ := store.Claim(ctx, "new:ref", time.Minute); !ok {
+		t.Error("Claim() after Release() = false")
+	}
+}
+
+func TestFileWebhookStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "webhooks.json")
+
+	store, err := NewFileWebhookStore(path, nil)
+	if err != nil {
+		t.Fatalf("NewFileWebhookStore() error = %v", err)
+	}
+	if ok, err := store.Claim(ctx, "link:ref", time.Hour); !ok || err != nil {
+		t.Fatalf("Claim() = %v, %v", ok, err)
+	}
+
+	// A restarted process still sees the claim
+	reopened, err := NewFileWebhookStore(path, nil)
+	if err != nil {
+		t.Fatalf("NewFileWebhookStore() reopen error = %v", err)
+	}
+	if ok, _ := reopened.Claim(ctx, "link:ref", time.Hour); ok {
+		t.Error("Claim() after reopen = true")
+	}
+	if err := reopened.Release(ctx, "link:ref"); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+
+	reopened, _ = NewFileWebhookStore(path, nil)
+	if ok, _ := reopened.Claim(ctx, "link:ref", time.Hour); !ok {
+		t.Error("Claim() after Release() and reopen = false")
+	}
+}
+
+func TestWebhooksVerifyDuplicate(t *testing.T) {
+	client, payload := newTestWebhookPayload(t, 901)
+	client.Client.webhookDeduper = NewWebhookDeduper(nil, 0)
+	ctx := context.Background()
+
+	if _, err := client.Webhooks.Verify(ctx, payload); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	webhook, err := client.Webhooks.Verify(ctx, payload)
+	if !errors.Is(err, apierror.ErrDuplicateWebhook) || webhook == nil {
+		t.Errorf("Verify() of redelivery = %v, %v", webhook, err)
+	}
+}
+
+func TestWebhooksVerifyRelease(t *testing.T) {
+	client, payload := newTestWebhookPayload(t, 903)
+	client.Client.webhookDeduper = NewWebhookDeduper(nil, 0)
+	ctx := context.Background()
+
+	// Processing the first delivery fails, so the caller releases it
+	webhook, err := client.Webhooks.Verify(ctx, payload)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if err := client.Webhooks.Release(ctx, webhook.Data); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+
+	if _, err := client.Webhooks.Verify(ctx, payload); err != nil {
+		t.Errorf("Verify() of the redelivery after Release() error = %v", err)
+	}
+	if _, err := client.Webhooks.Verify(ctx, payload); !errors.Is(err, apierror.ErrDuplicateWebhook) {
+		t.Errorf("Verify() of a processed redelivery error = %v, want ErrDuplicateWebhook", err)
+	}
+}
+
+func TestWebhooksVerifyDataDedupe(t *testing.T) {
+	client, err := NewPayOS(&PayOSOptions{ClientId: "id", ApiKey: "key", ChecksumKey: "checksum-key"})
+	if err != nil {
+		t.Fatalf("NewPayOS() error = %v", err)
+	}
+	store := NewMemoryWebhookStore(nil)
+	client.Client.webhookDeduper = NewWebhookDeduper(store, 0)
+	ctx := context.Background()
+
+	webhook := func(data map[string]interface{}) map[string]interface{} {
+		sig, err := signature.Sign(signature.SchemeBody, "checksum-key", data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return map[string]interface{}{"code": "00", "desc": "success", "data": data, "signature": sig}
+	}
+
+	paid := webhook(map[string]interface{}{"orderCode": 1, "paymentLinkId": "link", "reference": "ref"})
+	if _, err := client.Webhooks.VerifyData(ctx, paid); err != nil {
+		t.Fatalf("VerifyData() error = %v", err)
+	}
+	// The key is WebhookKey, so Verify and VerifyData see the same deliveries
+	if ok, _ := store.Claim(ctx, WebhookKey(&WebhookData{PaymentLinkId: "link", Reference: "ref"}), time.Hour); ok {
+		t.Error("VerifyData() did not claim the WebhookKey of the data")
+	}
+
+	if err := client.Webhooks.Release(ctx, &WebhookData{PaymentLinkId: "link", Reference: "ref"}); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if _, err := client.Webhooks.VerifyData(ctx, paid); err != nil {
+		t.Errorf("VerifyData() of the redelivery after Release() error = %v", err)
+	}
+
+	malformed := webhook(map[string]interface{}{"orderCode": "not a number", "paymentLinkId": "link", "reference": "other"})
+	var webhookErr *apierror.WebhookError
+	if _, err := client.Webhooks.VerifyData(ctx, malformed); !errors.As(err, &webhookErr) {
+		t.Errorf("VerifyData() of undecodable data error = %v, want a WebhookError", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		unidentified := webhook(map[string]interface{}{"orderCode": 2 + i, "paymentLinkId": "link"})
+		if _, err := client.Webhooks.VerifyData(ctx, unidentified); err != nil {
+			t.Errorf("VerifyData() of a webhook without reference error = %v", err)
+		}
+	}
+}
+
+func TestWebhooksHandlerDedupe(t *testing.T) {
+	client, payload := newTestWebhookPayload(t, 902)
+
+	calls, duplicates := 0, 0
+	callbackErr := errors.New("database unavailable")
+	handler := client.Webhooks.Handler(&WebhookHandlerOptions{
+		Deduper: NewWebhookDeduper(nil, 0),
+		OnPaymentSucceeded: func(ctx context.Context, data *WebhookData) error {
+			calls++
+			return callbackErr
+		},
+		OnDuplicate: func(ctx context.Context, data *WebhookData) {
+			duplicates++
+		},
+	})
+
+	serve := func() int {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(payload)))
+		return rec.Code
+	}
+
+	// A failed callback releases the claim so the redelivery is processed
+	if code := serve(); code != http.StatusInternalServerError {
+		t.Errorf("failed delivery status = %d", code)
+	}
+	callbackErr = nil
+	if code := serve(); code != http.StatusOK {
+		t.Errorf("redelivery status = %d", code)
+	}
+	if code := serve(); code != http.StatusOK {
+		t.Errorf("duplicate status = %d", code)
+	}
+	if calls != 2 || duplicates != 1 {
+		t.Errorf("calls = %d, duplicates = %d", calls, duplicates)
+	}
+}
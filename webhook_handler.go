@@ -0,0 +1,219 @@
+package payos
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/payOSHQ/payos-lib-golang/v2/apierror"
+	"github.com/payOSHQ/payos-lib-golang/v2/signature"
+)
+
+// DefaultWebhookMaxBodyBytes is the default body size limit of the webhook handler
+const DefaultWebhookMaxBodyBytes = 1 << 20
+
+// WebhookHandlerOptions configures the http.Handler returned by Webhooks.Handler
+type WebhookHandlerOptions struct {
+	// MaxBodyBytes limits the size of the webhook body
+	// Defaults to DefaultWebhookMaxBodyBytes
+	MaxBodyBytes int64
+
+	// OnPaymentSucceeded is called for verified webhooks of successful payments
+	// Returning an error answers 500 so payOS delivers the webhook again
+	OnPaymentSucceeded func(ctx context.Context, data *WebhookData) error
+
+	// OnPaymentFailed is called for verified webhooks with a non-success code
+	// Returning an error answers 500 so payOS delivers the webhook again
+	OnPaymentFailed func(ctx context.Context, data *WebhookData) error
+
+	// OnError is called with every rejected webhook and callback error
+	OnError func(r *http.Request, err error)
+
+	// Deduper skips the callbacks of redelivered webhooks
+	// Defaults to PayOSOptions.WebhookDeduper
+	Deduper *WebhookDeduper
+
+	// OnDuplicate is called instead of the payment callbacks for a redelivered webhook
+	// Duplicates are answered with 200 so payOS stops delivering them
+	OnDuplicate func(ctx context.Context, data *WebhookData)
+}
+
+// Verify verifies a raw webhook body and decodes it
+// The signature is checked over the data bytes as received
+// With a WebhookDeduper configured, a redelivered webhook is returned with an error matching apierror.ErrDuplicateWebhook
+// The webhook is claimed before it is returned, so call Release when processing it fails or its redelivery is reported as a duplicate
+func (w *Webhooks) Verify(ctx context.Context, body []byte) (webhook *Webhook, err error) {
+	defer func(start time.Time) { w.client.observeWebhook(ctx, start, err) }(w.client.clock.Now())
+
+	creds, err := w.client.loadCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	webhook, err = verifyWebhookBody(body, creds)
+	if err != nil {
+		return nil, err
+	}
+	if w.client.webhookDeduper != nil {
+		if err := w.client.webhookDeduper.Claim(ctx, webhook.Data); err != nil {
+			return webhook, err
+		}
+	}
+	return webhook, nil
+}
+
+// Handler returns an http.Handler that verifies webhooks and dispatches them to the callbacks
+func (w *Webhooks) Handler(opts *WebhookHandlerOptions) http.Handler {
+	if opts == nil {
+		opts = &WebhookHandlerOptions{}
+	}
+	maxBodyBytes := opts.MaxBodyBytes
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = DefaultWebhookMaxBodyBytes
+	}
+	deduper := opts.Deduper
+	if deduper == nil {
+		deduper = w.client.webhookDeduper
+	}
+
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		fail := func(status int, err error) {
+			if opts.OnError != nil {
+				opts.OnError(r, err)
+			}
+			writeWebhookResponse(rw, status, err.Error())
+		}
+
+		if r.Method != http.MethodPost {
+			rw.Header().Set("Allow", http.MethodPost)
+			fail(http.StatusMethodNotAllowed, apierror.NewWebhookError("method not allowed"))
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(rw, r.Body, maxBodyBytes))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				fail(http.StatusRequestEntityTooLarge, apierror.NewWebhookError(fmt.Sprintf("webhook body exceeds %d bytes", maxBodyBytes)))
+				return
+			}
+			fail(http.StatusBadRequest, apierror.NewWebhookError("failed to read webhook body"))
+			return
+		}
+
+		start := w.client.clock.Now()
+		creds, err := w.client.loadCredentials(r.Context())
+		var webhook *Webhook
+		if err == nil {
+			webhook, err = verifyWebhookBody(body, creds)
+		}
+		if err != nil {
+			w.client.observeWebhook(r.Context(), start, err)
+			status := http.StatusBadRequest
+			switch {
+			case errors.Is(err, apierror.ErrInvalidSignature):
+				status = http.StatusUnauthorized
+			case errors.Is(err, apierror.ErrCredentials):
+				// payOS delivers the webhook again once the credentials can be loaded
+				status = http.StatusInternalServerError
+			}
+			fail(status, err)
+			return
+		}
+
+		// Duplicates are recorded in the metrics as such, so the claim comes first
+		if deduper != nil {
+			err = deduper.Claim(r.Context(), webhook.Data)
+		}
+		w.client.observeWebhook(r.Context(), start, err)
+		if err != nil {
+			if !errors.Is(err, apierror.ErrDuplicateWebhook) {
+				fail(http.StatusInternalServerError, err)
+				return
+			}
+			if opts.OnDuplicate != nil {
+				opts.OnDuplicate(r.Context(), webhook.Data)
+			}
+			writeWebhookResponse(rw, http.StatusOK, "")
+			return
+		}
+
+		callback := opts.OnPaymentSucceeded
+		if !webhook.IsSuccess() {
+			callback = opts.OnPaymentFailed
+		}
+		if callback != nil {
+			if err := callback(r.Context(), webhook.Data); err != nil {
+				// Release the claim so the redelivery is processed again
+				if deduper != nil {
+					deduper.Release(r.Context(), webhook.Data)
+				}
+
+				// The callback error is reported to OnError but not sent to payOS
+				if opts.OnError != nil {
+					opts.OnError(r, err)
+				}
+				writeWebhookResponse(rw, http.StatusInternalServerError, "webhook processing failed")
+				return
+			}
+		}
+
+		writeWebhookResponse(rw, http.StatusOK, "")
+	})
+}
+
+// IsSuccess reports whether the webhook is for a successful payment
+func (wh *Webhook) IsSuccess() bool {
+	return wh.Code == "00" && wh.Data != nil && wh.Data.Code == "00"
+}
+
+// verifyWebhookBody decodes a raw webhook body and verifies its signature against the checksum keys of creds
+func verifyWebhookBody(body []byte, creds Credentials) (*Webhook, error) {
+	var raw struct {
+		Code      string          `json:"code"`
+		Desc      string          `json:"desc"`
+		Success   *bool           `json:"success"`
+		Data      json.RawMessage `json:"data"`
+		Signature string          `json:"signature"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, apierror.NewPayOSError("Invalid webhook body format")
+	}
+	if len(raw.Data) == 0 || string(raw.Data) == "null" {
+		return nil, apierror.NewPayOSError("data invalid")
+	}
+	if raw.Signature == "" {
+		return nil, apierror.NewPayOSError("signature invalid")
+	}
+
+	if err := creds.verify(signature.SchemeBody, raw.Data, raw.Signature); err != nil {
+		return nil, &apierror.InvalidSignatureError{Message: "data not integrity", Err: err}
+	}
+
+	var data WebhookData
+	if err := json.Unmarshal(raw.Data, &data); err != nil {
+		return nil, apierror.NewPayOSError("data invalid")
+	}
+
+	return &Webhook{
+		Code:      raw.Code,
+		Desc:      raw.Desc,
+		Success:   raw.Success,
+		Data:      &data,
+		Signature: raw.Signature,
+	}, nil
+}
+
+// writeWebhookResponse answers a webhook delivery
+func writeWebhookResponse(rw http.ResponseWriter, status int, message string) {
+	resp := map[string]interface{}{"success": status == http.StatusOK}
+	if message != "" {
+		resp["error"] = message
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	json.NewEncoder(rw).Encode(resp)
+}
@@ -0,0 +1,129 @@
+package payos
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/payOSHQ/payos-lib-golang/v2/apierror"
+)
+
+// newTestWebhookPayload creates a paid payment link and returns its signed webhook body
+func newTestWebhookPayload(t *testing.T, orderCode int64) (*PayOS, []byte) {
+	t.Helper()
+	client, srv := newTestPayOS(t)
+
+	if _, err := client.PaymentRequests.Create(context.Background(), testPaymentLinkRequest(orderCode)); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := srv.MarkPaid(orderCode); err != nil {
+		t.Fatalf("MarkPaid() error = %v", err)
+	}
+	payload, err := srv.WebhookPayload(orderCode)
+	if err != nil {
+		t.Fatalf("WebhookPayload() error = %v", err)
+	}
+	return client, payload
+}
+
+func TestWebhooksVerify(t *testing.T) {
+	client, payload := newTestWebhookPayload(t, 801)
+
+	webhook, err := client.Webhooks.Verify(context.Background(), payload)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if !webhook.IsSuccess() || webhook.Data.OrderCode != 801 || webhook.Data.Amount != 2000 {
+		t.Errorf("Verify() = %+v", webhook.Data)
+	}
+
+	tampered := bytes.Replace(payload, []byte(`"amount":2000`), []byte(`"amount":1`), 1)
+	if _, err := client.Webhooks.Verify(context.Background(), tampered); !errors.Is(err, apierror.ErrInvalidSignature) {
+		t.Errorf("Verify() of tampered payload error = %v", err)
+	}
+}
+
+func TestWebhooksHandler(t *testing.T) {
+	client, payload := newTestWebhookPayload(t, 802)
+
+	var succeeded []*WebhookData
+	var callbackErr error
+	var reported []error
+	handler := client.Webhooks.Handler(&WebhookHandlerOptions{
+		MaxBodyBytes: 4096,
+		OnPaymentSucceeded: func(ctx context.Context, data *WebhookData) error {
+			succeeded = append(succeeded, data)
+			return callbackErr
+		},
+		OnError: func(r *http.Request, err error) {
+			reported = append(reported, err)
+		},
+	})
+
+	serve := func(method string, body []byte) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, "/webhook", bytes.NewReader(body)))
+		return rec
+	}
+
+	if rec := serve(http.MethodPost, payload); rec.Code != http.StatusOK {
+		t.Errorf("valid webhook status = %d, body = %s", rec.Code, rec.Body)
+	}
+	if len(succeeded) != 1 || succeeded[0].OrderCode != 802 {
+		t.Fatalf("OnPaymentSucceeded calls = %v", succeeded)
+	}
+
+	callbackErr = errors.New("database unavailable")
+	rec := serve(http.MethodPost, payload)
+	if rec.Code != http.StatusInternalServerError || strings.Contains(rec.Body.String(), "database") {
+		t.Errorf("callback error status = %d, body = %s", rec.Code, rec.Body)
+	}
+
+	tampered := bytes.Replace(payload, []byte(`"amount":2000`), []byte(`"amount":1`), 1)
+	tests := []struct {
+		name   string
+		method string
+		body   []byte
+		want   int
+	}{
+		{"tampered", http.MethodPost, tampered, http.StatusUnauthorized},
+		{"malformed", http.MethodPost, []byte("{"), http.StatusBadRequest},
+		{"too large", http.MethodPost, bytes.Repeat([]byte(" "), 5000), http.StatusRequestEntityTooLarge},
+		{"method", http.MethodGet, nil, http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		if rec := serve(tt.method, tt.body); rec.Code != tt.want {
+			t.Errorf("%s status = %d, want %d", tt.name, rec.Code, tt.want)
+		}
+	}
+	if len(succeeded) != 2 || len(reported) != 1+len(tests) {
+		t.Errorf("succeeded = %d, reported = %d", len(succeeded), len(reported))
+	}
+}
+
+func TestWebhooksHandlerFailedPayment(t *testing.T) {
+	client, payload := newTestWebhookPayload(t, 803)
+	failed := bytes.Replace(payload, []byte(`"code":"00"`), []byte(`"code":"01"`), 1)
+
+	var calls []string
+	handler := client.Webhooks.Handler(&WebhookHandlerOptions{
+		OnPaymentSucceeded: func(ctx context.Context, data *WebhookData) error {
+			calls = append(calls, "succeeded")
+			return nil
+		},
+		OnPaymentFailed: func(ctx context.Context, data *WebhookData) error {
+			calls = append(calls, "failed")
+			return nil
+		},
+	})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(failed)))
+	if rec.Code != http.StatusOK || len(calls) != 1 || calls[0] != "failed" {
+		t.Errorf("status = %d, calls = %v", rec.Code, calls)
+	}
+}
@@ -2,9 +2,12 @@ package payos
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"time"
 
-	"github.com/payOSHQ/payos-lib-golang/v2/internal/apierror"
-	"github.com/payOSHQ/payos-lib-golang/v2/internal/crypto"
+	"github.com/payOSHQ/payos-lib-golang/v2/apierror"
+	"github.com/payOSHQ/payos-lib-golang/v2/signature"
 )
 
 // ========================
@@ -79,7 +82,8 @@ type Webhooks struct {
 }
 
 // Confirm validates the webhook URL and updates it if successful
-func (w *Webhooks) Confirm(ctx context.Context, webhookUrl string) (string, error) {
+func (w *Webhooks) Confirm(ctx context.Context, webhookUrl string, opts ...RequestOption) (string, error) {
+	opts = withOperation("Webhooks.Confirm", opts)
 	if webhookUrl == "" {
 		return "", apierror.NewPayOSError("invalid params")
 	}
@@ -88,7 +92,7 @@ func (w *Webhooks) Confirm(ctx context.Context, webhookUrl string) (string, erro
 		"webhookUrl": webhookUrl,
 	}
 
-	_, err := w.client.Post(ctx, "/confirm-webhook", body, nil, nil)
+	_, err := w.client.Post(ctx, "/confirm-webhook", body, nil, nil, opts...)
 	if err != nil {
 		return "", err
 	}
@@ -97,13 +101,55 @@ func (w *Webhooks) Confirm(ctx context.Context, webhookUrl string) (string, erro
 }
 
 // VerifyData verifies data received via webhook after payment
-func (w *Webhooks) VerifyData(ctx context.Context, webhookBody interface{}) (interface{}, error) {
+// With a WebhookDeduper configured, a redelivered webhook returns its data with an error matching apierror.ErrDuplicateWebhook
+// The webhook is claimed before it is returned, so call Release when processing it fails or its redelivery is reported as a duplicate
+func (w *Webhooks) VerifyData(ctx context.Context, webhookBody interface{}) (data interface{}, err error) {
+	defer func(start time.Time) { w.client.observeWebhook(ctx, start, err) }(w.client.clock.Now())
+
 	// This is a utility function that doesn't require the HTTP client
-	return verifyWebhookSignature(webhookBody, w.client.checksumKey)
+	creds, err := w.client.loadCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data, err = verifyWebhookSignature(webhookBody, creds)
+	if err != nil || w.client.webhookDeduper == nil {
+		return data, err
+	}
+
+	webhookData, err := decodeWebhookData(data)
+	if err != nil {
+		return data, err
+	}
+	if err := w.client.webhookDeduper.Claim(ctx, webhookData); err != nil {
+		return data, err
+	}
+	return data, nil
+}
+
+// Release forgets a webhook claimed by Verify or VerifyData so its redelivery is processed again
+// Call it when processing the webhook fails; it does nothing without a WebhookDeduper
+func (w *Webhooks) Release(ctx context.Context, data *WebhookData) error {
+	if w.client.webhookDeduper == nil {
+		return nil
+	}
+	return w.client.webhookDeduper.Release(ctx, data)
+}
+
+// decodeWebhookData decodes the verified data of VerifyData into WebhookData
+func decodeWebhookData(data interface{}) (*WebhookData, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, apierror.NewWebhookError(fmt.Sprintf("failed to decode webhook data: %v", err))
+	}
+	var webhookData WebhookData
+	if err := json.Unmarshal(raw, &webhookData); err != nil {
+		return nil, apierror.NewWebhookError(fmt.Sprintf("failed to decode webhook data: %v", err))
+	}
+	return &webhookData, nil
 }
 
 // verifyWebhookSignature is a helper function to verify webhook signatures
-func verifyWebhookSignature(webhookBody interface{}, checksumKey string) (interface{}, error) {
+func verifyWebhookSignature(webhookBody interface{}, creds Credentials) (interface{}, error) {
 	// Use type assertion to get webhook data
 	webhook, ok := webhookBody.(map[string]interface{})
 	if !ok {
@@ -111,22 +157,17 @@ func verifyWebhookSignature(webhookBody interface{}, checksumKey string) (interf
 	}
 
 	data, hasData := webhook["data"]
-	signature, hasSig := webhook["signature"]
+	sig, _ := webhook["signature"].(string)
 
 	if !hasData || data == nil {
 		return nil, apierror.NewPayOSError("data invalid")
 	}
-	if !hasSig || signature == "" {
+	if sig == "" {
 		return nil, apierror.NewPayOSError("signature invalid")
 	}
 
-	signData, err := crypto.CreateSignatureFromObj(data, checksumKey)
-	if err != nil {
-		return nil, apierror.NewPayOSError("internal server error")
-	}
-
-	if signData != signature.(string) {
-		return nil, apierror.NewPayOSError("data not integrity")
+	if err := creds.verify(signature.SchemeBody, data, sig); err != nil {
+		return nil, &apierror.InvalidSignatureError{Message: "data not integrity", Err: err}
 	}
 
 	return data, nil
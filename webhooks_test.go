@@ -1,6 +1,13 @@
 package payos
 
-import "testing"
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
 
 func TestWebhooks(t *testing.T) {
 	// TODO: implement test
@@ -8,13 +15,52 @@ func TestWebhooks(t *testing.T) {
 }
 
 func TestConfirmWebhook(t *testing.T) {
-	// TODO: implement test
-	t.Skip("Test implementation pending")
+	client, srv := newTestPayOS(t)
+
+	url, err := client.Webhooks.Confirm(context.Background(), "https://example.com/webhook")
+	if err != nil {
+		t.Fatalf("Confirm() error = %v", err)
+	}
+	if url != "https://example.com/webhook" || srv.WebhookURL() != url {
+		t.Errorf("Confirm() = %s, registered %s", url, srv.WebhookURL())
+	}
 }
 
 func TestVerifyPaymentWebhookData(t *testing.T) {
-	// TODO: implement test
-	t.Skip("Test implementation pending")
+	client, srv := newTestPayOS(t)
+	ctx := context.Background()
+
+	received := make(chan map[string]interface{}, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var payload map[string]interface{}
+		json.Unmarshal(body, &payload)
+		received <- payload
+	}))
+	defer receiver.Close()
+
+	if _, err := client.Webhooks.Confirm(ctx, receiver.URL); err != nil {
+		t.Fatalf("Confirm() error = %v", err)
+	}
+	if _, err := client.PaymentRequests.Create(ctx, testPaymentLinkRequest(1001)); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := srv.MarkPaid(int64(1001)); err != nil {
+		t.Fatalf("MarkPaid() error = %v", err)
+	}
+	if _, err := srv.SendWebhook(ctx, int64(1001)); err != nil {
+		t.Fatalf("SendWebhook() error = %v", err)
+	}
+
+	payload := <-received
+	if _, err := client.Webhooks.VerifyData(ctx, payload); err != nil {
+		t.Errorf("VerifyData() error = %v", err)
+	}
+
+	payload["data"].(map[string]interface{})["amount"] = 1
+	if _, err := client.Webhooks.VerifyData(ctx, payload); err == nil {
+		t.Error("VerifyData() of tampered payload error = nil")
+	}
 }
 
 func TestWebhookSignatureValidation(t *testing.T) {